@@ -63,6 +63,7 @@ func main() {
 			os.Exit(1)
 		}
 		ctx = client.WithConfig(ctx, cfg)
+		ctx = logging.WithOptions(ctx, &logging.Options{})
 		if ctx, err = logging.InitContext(ctx, "cli", logging.RotateDaily, false); err != nil {
 			fmt.Fprintln(os.Stderr, err.Error())
 			os.Exit(1)
@@ -44,8 +44,12 @@ import (
 // It's expected that the client that makes the call will update any unqualified service port identifiers
 // with the ones in the returned PreparedIntercept.
 func (s *State) PrepareIntercept(ctx context.Context, cr *managerrpc.CreateInterceptRequest) (*managerrpc.PreparedIntercept, error) {
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+	// A client that is prepared to wait longer (or shorter) for the agent to be installed will send a deadline.
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
 
 	interceptError := func(err error) (*managerrpc.PreparedIntercept, error) {
 		if _, ok := status.FromError(err); ok {
@@ -67,7 +71,7 @@ func (s *State) PrepareIntercept(ctx context.Context, cr *managerrpc.CreateInter
 	if err != nil {
 		return interceptError(err)
 	}
-	_, ic, err := findIntercept(ac, spec)
+	_, ic, err := agentconfig.FindIntercept(ac, spec)
 	if err != nil {
 		return interceptError(err)
 	}
@@ -197,7 +201,7 @@ func (s *State) loadAgentConfig(
 	}
 
 	if y, ok := cm.Data[wl.GetName()]; ok {
-		if ac, err = unmarshalConfigMapEntry(y, wl.GetName(), wl.GetNamespace()); err != nil {
+		if ac, err = agentconfig.UnmarshalConfigMapEntry(y, wl.GetName(), wl.GetNamespace()); err != nil {
 			return nil, err
 		}
 		if ac.Create {
@@ -311,7 +315,7 @@ func waitForConfigMapUpdate(ctx context.Context, cmAPI typed.ConfigMapInterface,
 			}
 			if m, ok := ev.Object.(*core.ConfigMap); ok {
 				if y, ok := m.Data[agentName]; ok {
-					conf, err := unmarshalConfigMapEntry(y, agentName, namespace)
+					conf, err := agentconfig.UnmarshalConfigMapEntry(y, agentName, namespace)
 					if err != nil {
 						return nil, err
 					}
@@ -350,69 +354,6 @@ func (s *State) waitForAgent(ctx context.Context, name, namespace string) error
 	}
 }
 
-func unmarshalConfigMapEntry(y string, name, namespace string) (*agentconfig.Sidecar, error) {
-	conf := agentconfig.Sidecar{}
-	if err := yaml.Unmarshal([]byte(y), &conf); err != nil {
-		return nil, fmt.Errorf("failed to parse entry for %s in ConfigMap %s.%s: %w", name, agentconfig.ConfigMap, namespace, err)
-	}
-	return &conf, nil
-}
-
-// findIntercept finds the intercept configuration that matches the given InterceptSpec's service/service port
-func findIntercept(ac *agentconfig.Sidecar, spec *managerrpc.InterceptSpec) (foundCN *agentconfig.Container, foundIC *agentconfig.Intercept, err error) {
-	spi := agentconfig.PortIdentifier(spec.ServicePortIdentifier)
-	for _, cn := range ac.Containers {
-		for _, ic := range cn.Intercepts {
-			if !(spec.ServiceName == "" || spec.ServiceName == ic.ServiceName) {
-				continue
-			}
-			if !(spi == "" || agentconfig.IsInterceptFor(spi, ic)) {
-				continue
-			}
-			if foundIC == nil {
-				foundCN = cn
-				foundIC = ic
-				continue
-			}
-			var msg string
-			switch {
-			case spec.ServiceName == "" && spi == "":
-				msg = fmt.Sprintf("%s %s.%s has multiple interceptable service ports.\n"+
-					"Please specify the service and/or service port you want to intercept "+
-					"by passing the --service=<svc> and/or --port=<local:svcPortName> flag.",
-					ac.WorkloadKind, ac.WorkloadName, ac.Namespace)
-			case spec.ServiceName == "":
-				msg = fmt.Sprintf("%s %s.%s has multiple interceptable services with port %s.\n"+
-					"Please specify the service you want to intercept by passing the --service=<svc> flag.",
-					ac.WorkloadKind, ac.WorkloadName, ac.Namespace, spi)
-			case spi == "":
-				msg = fmt.Sprintf("%s %s.%s has multiple interceptable ports in service %s.\n"+
-					"Please specify the port you want to intercept by passing the --port=<local:svcPortName> flag.",
-					ac.WorkloadKind, ac.WorkloadName, ac.Namespace, spec.ServiceName)
-			default:
-				msg = fmt.Sprintf("%s %s.%s intercept config is broken. Service %s, port %s is declared more than once\n",
-					ac.WorkloadKind, ac.WorkloadName, ac.Namespace, spec.ServiceName, spi)
-			}
-			return nil, nil, errcat.User.New(msg)
-		}
-	}
-	if foundIC != nil {
-		return foundCN, foundIC, nil
-	}
-
-	ss := ""
-	if spec.ServiceName != "" {
-		if spi != "" {
-			ss = fmt.Sprintf(" matching service %s, port %s", spec.ServiceName, spi)
-		} else {
-			ss = fmt.Sprintf(" matching service %s", spec.ServiceName)
-		}
-	} else if spi != "" {
-		ss = fmt.Sprintf(" matching port %s", spi)
-	}
-	return nil, nil, errcat.User.Newf("%s %s.%s has no interceptable port%s", ac.WorkloadKind, ac.WorkloadName, ac.Namespace, ss)
-}
-
 type InterceptFinalizer func(ctx context.Context, interceptInfo *managerrpc.InterceptInfo) error
 
 type interceptState struct {
@@ -59,6 +59,14 @@ func (s *cliSuite) Test_StatusWithJSON() {
 	s.False(status.UserDaemon.Running)
 }
 
+func (s *cliSuite) Test_HealthNotConnected() {
+	itest.TelepresenceQuitOk(s.Context())
+	stdout, _, err := itest.Telepresence(s.Context(), "health")
+	s.Error(err)
+	s.Contains(stdout, "Telepresence is not ready")
+	s.Regexp(`user-daemon\s*: not ready, not running`, stdout)
+}
+
 type statusResponseRootDaemon struct {
 	Running           bool     `json:"running,omitempty"`
 	AlsoProxySubnets  []string `json:"also_proxy_subnets,omitempty"`
@@ -43,3 +43,9 @@ func (s *connectedSuite) Test_StatusWithJSON() {
 	s.NotEmpty(status.UserDaemon.KubernetesContext)
 	s.NotEmpty(status.UserDaemon.InstallID)
 }
+
+func (s *connectedSuite) Test_Health() {
+	stdout := itest.TelepresenceOk(s.Context(), "--health", "--timeout", "30s")
+	s.Contains(stdout, "Telepresence is ready")
+	s.Regexp(`outbound\s*: ready`, stdout)
+}
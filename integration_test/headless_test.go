@@ -53,7 +53,7 @@ func (s *connectedSuite) Test_SuccessfullyInterceptsHeadlessService() {
 					`never gets install agent`)
 			}
 			stdout := itest.TelepresenceOk(ctx, "intercept", "--namespace", s.AppNamespace(), "--mount", "false", svc, "--port", strconv.Itoa(svcPort))
-			require.Contains(stdout, "Using StatefulSet echo-headless")
+			require.Contains(stdout, "Using statefulset echo-headless")
 			s.CapturePodLogs(ctx, "service=echo-headless", "traffic-agent", s.AppNamespace())
 
 			defer func() {
@@ -44,7 +44,7 @@ func (s *helmSuite) Test_HelmCanInterceptInManagedNamespace() {
 	defer itest.TelepresenceOk(ctx, "leave", s.ServiceName()+"-"+s.AppNamespace())
 
 	stdout := itest.TelepresenceOk(ctx, "intercept", "--namespace", s.AppNamespace(), "--mount", "false", s.ServiceName(), "--port", "9090")
-	s.Contains(stdout, "Using Deployment "+s.ServiceName())
+	s.Contains(stdout, "Using deployment "+s.ServiceName())
 	stdout = itest.TelepresenceOk(ctx, "list", "--namespace", s.AppNamespace(), "--intercepts")
 	s.Contains(stdout, s.ServiceName()+": intercepted")
 }
@@ -106,7 +106,7 @@ func (s *helmSuite) Test_HelmMultipleInstalls() {
 		ctx := itest.WithEnv(s.Context(), map[string]string{"TELEPRESENCE_MANAGER_NAMESPACE": s.mgrSpace2})
 		defer itest.TelepresenceQuitOk(ctx)
 		stdout := itest.TelepresenceOk(ctx, "intercept", "--namespace", s.appSpace2, "--mount", "false", svc, "--port", "9090")
-		s.Contains(stdout, "Using Deployment "+svc)
+		s.Contains(stdout, "Using deployment "+svc)
 		stdout = itest.TelepresenceOk(ctx, "list", "--namespace", s.appSpace2, "--intercepts")
 		s.Contains(stdout, svc+": intercepted")
 	})
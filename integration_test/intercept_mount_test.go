@@ -43,7 +43,7 @@ func (s *interceptMountSuite) SetupSuite() {
 	var port int
 	port, s.cancelLocal = itest.StartLocalHttpEchoServer(ctx, s.ServiceName())
 	stdout := itest.TelepresenceOk(ctx, "intercept", "--namespace", s.AppNamespace(), s.ServiceName(), "--mount", s.mountPoint, "--port", strconv.Itoa(port))
-	s.Contains(stdout, "Using Deployment "+s.ServiceName())
+	s.Contains(stdout, "Using deployment "+s.ServiceName())
 	s.CapturePodLogs(ctx, "app=echo", "traffic-agent", s.AppNamespace())
 }
 
@@ -105,7 +105,7 @@ func (s *singleServiceSuite) Test_InterceptMountRelative() {
 	defer func() {
 		itest.TelepresenceOk(ctx, "leave", fmt.Sprintf("%s-%s", s.ServiceName(), s.AppNamespace()))
 	}()
-	s.Contains(stdout, "Using Deployment "+s.ServiceName())
+	s.Contains(stdout, "Using deployment "+s.ServiceName())
 
 	stdout = itest.TelepresenceOk(ctx, "--namespace", s.AppNamespace(), "list", "--intercepts")
 	s.Regexp(s.ServiceName()+`\s*: intercepted`, stdout)
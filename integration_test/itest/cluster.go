@@ -152,11 +152,8 @@ func (s *cluster) ensureQuitAndLoggedOut(ctx context.Context) {
 	// Ensure that telepresence is not logged in
 	_, _, _ = Telepresence(ctx, "logout") //nolint:dogsled // don't care about any of the returns
 
-	// Ensure that no telepresence is running when the tests start
-	_, _, _ = Telepresence(ctx, "quit", "-ur") //nolint:dogsled // don't care about any of the returns
-
-	// Ensure that the daemon-socket is non-existent.
-	_ = rmAsRoot(client.DaemonSocketName)
+	// Ensure that no telepresence is running and that no stale sockets remain when the tests start
+	_, _, _ = Telepresence(ctx, "quit", "--kill-daemons") //nolint:dogsled // don't care about any of the returns
 }
 
 func (s *cluster) ensureExecutable(ctx context.Context, errs chan<- error, wg *sync.WaitGroup) {
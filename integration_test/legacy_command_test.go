@@ -17,7 +17,7 @@ func (s *singleServiceSuite) Test_LegacySwapDeploymentDoesIntercept() {
 		"--namespace", s.AppNamespace(), "--mount", "false", "--run", "sleep", "1")
 	require.NoError(err)
 	require.Contains(stderr, "Legacy Telepresence command used")
-	require.Contains(stderr, "Using Deployment "+s.ServiceName())
+	require.Contains(stderr, "Using deployment "+s.ServiceName())
 
 	// Since legacy Telepresence commands are detected and translated in the
 	// RunSubcommands function, so we ensure that the help text is *not* being
@@ -48,7 +48,7 @@ func (s *multipleInterceptsSuite) SetupSuite() {
 			defer wg.Done()
 			svc := fmt.Sprintf("%s-%d", s.Name(), i)
 			stdout := itest.TelepresenceOk(ctx, "intercept", "--namespace", s.AppNamespace(), svc, "--mount", "false", "--port", strconv.Itoa(s.servicePort[i]))
-			s.Contains(stdout, "Using Deployment "+svc)
+			s.Contains(stdout, "Using deployment "+svc)
 			s.NoError(s.RolloutStatusWait(ctx, "deploy/"+svc))
 		}(i)
 	}
@@ -177,3 +177,34 @@ func (s *connectedSuite) Test_UnnamedUdpAndTcpPort() {
 		wg.Wait()
 	})
 }
+
+// Test_RepeatedPortFlag tests that several ports of the same workload can be intercepted
+// using one command with a repeated --port flag, and that all of them are removed by leave.
+func (s *connectedSuite) Test_RepeatedPortFlag() {
+	ctx := s.Context()
+	dep := "echo-double-one-unnamed"
+	s.ApplyTestApp(ctx, dep, "deploy/"+dep)
+	defer s.KubectlOk(ctx, "delete", "deploy", dep)
+
+	require := s.Require()
+	require.NoError(s.Kubectl(ctx, "expose", "deploy", dep, "--port", "80", "--target-port", "8080", "--name", dep+"-"+"80"))
+	defer s.KubectlOk(ctx, "delete", "svc", dep+"-"+"80")
+	require.NoError(s.Kubectl(ctx, "expose", "deploy", dep, "--port", "81", "--target-port", "8081", "--name", dep+"-"+"81"))
+	defer s.KubectlOk(ctx, "delete", "svc", dep+"-"+"81")
+
+	localPort80, cancel80 := itest.StartLocalHttpEchoServer(ctx, dep+"-80")
+	defer cancel80()
+	localPort81, cancel81 := itest.StartLocalHttpEchoServer(ctx, dep+"-81")
+	defer cancel81()
+
+	name := dep + "-" + s.AppNamespace()
+	stdout := itest.TelepresenceOk(ctx, "intercept", "-n", s.AppNamespace(), "--mount", "false",
+		"-p", fmt.Sprintf("%d:80", localPort80), "-p", fmt.Sprintf("%d:81", localPort81), dep)
+	s.Contains(stdout, name+"-"+fmt.Sprint(localPort81))
+	itest.PingInterceptedEchoServer(ctx, dep+"-80", "80")
+	itest.PingInterceptedEchoServer(ctx, dep+"-81", "81")
+
+	itest.TelepresenceOk(ctx, "leave", name)
+	stdout = itest.TelepresenceOk(ctx, "list", "--intercepts")
+	s.NotContains(stdout, name)
+}
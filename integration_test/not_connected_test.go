@@ -6,6 +6,7 @@ import (
 	"github.com/stretchr/testify/suite"
 
 	"github.com/telepresenceio/telepresence/v2/integration_test/itest"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
 )
 
 type notConnectedSuite struct {
@@ -58,3 +59,43 @@ func (s *notConnectedSuite) Test_NonExistentContext() {
 	s.Contains(stderr, `"not-likely-to-exist" does not exist`)
 	itest.TelepresenceDisconnectOk(ctx)
 }
+
+func (s *notConnectedSuite) Test_ValidateContexts() {
+	ctx := s.Context()
+	stdout, stderr, err := itest.Telepresence(ctx, "connect", "--validate-contexts", "default,not-likely-to-exist")
+	s.Error(err)
+	s.Regexp(`(?m)^default\s+\S+\s+ok, server version `, stdout)
+	s.Regexp(`(?m)^not-likely-to-exist\s+error: context "not-likely-to-exist" does not exist`, stdout)
+	s.Contains(stderr, "validation failed for 1 of 2 contexts: not-likely-to-exist")
+	s.NotContains(stdout, "Connected to context")
+}
+
+func (s *notConnectedSuite) Test_KillDaemons() {
+	ctx := s.Context()
+	itest.TelepresenceOk(ctx, "connect")
+	itest.TelepresenceOk(ctx, "quit", "--kill-daemons")
+	for _, socket := range []string{client.ConnectorSocketName, client.DaemonSocketName} {
+		exists, err := client.SocketExists(socket)
+		s.NoError(err)
+		s.False(exists, "socket %s still exists", socket)
+	}
+
+	// Nothing is running, so this must be a no-op
+	itest.TelepresenceOk(ctx, "quit", "--kill-daemons")
+}
+
+func (s *notConnectedSuite) Test_SwitchContext() {
+	ctx := s.Context()
+	itest.TelepresenceOk(ctx, "connect")
+	defer itest.TelepresenceQuitOk(ctx)
+
+	// An invalid target context must be rejected without dropping the current session
+	_, stderr, err := itest.Telepresence(ctx, "connect", "--switch-context", "not-likely-to-exist")
+	s.Error(err)
+	s.Contains(stderr, `"not-likely-to-exist" does not exist`)
+	s.Contains(itest.TelepresenceOk(ctx, "status"), "Status            : Connected")
+
+	stdout := itest.TelepresenceOk(ctx, "connect", "--switch-namespace", s.AppNamespace())
+	s.Contains(stdout, "Connected to context")
+	s.Contains(itest.TelepresenceOk(ctx, "status"), "Status            : Connected")
+}
@@ -20,7 +20,7 @@ func (s *connectedSuite) Test_ToPodPortForwarding() {
 	require := s.Require()
 	stdout := itest.TelepresenceOk(ctx, "intercept", "--namespace", s.AppNamespace(), "--mount", "false", svc, "--port", "8080", "--to-pod", "8081", "--to-pod", "8082")
 	defer itest.TelepresenceOk(ctx, "leave", svc+"-"+s.AppNamespace())
-	require.Contains(stdout, "Using Deployment "+svc)
+	require.Contains(stdout, "Using deployment "+svc)
 	stdout = itest.TelepresenceOk(ctx, "list", "--namespace", s.AppNamespace(), "--intercepts")
 	require.Contains(stdout, svc+": intercepted")
 
@@ -60,7 +60,7 @@ func (s *connectedSuite) Test_ToPodUDPPortForwarding() {
 	require := s.Require()
 	stdout := itest.TelepresenceOk(ctx, "intercept", "--namespace", s.AppNamespace(), "--mount", "false", svc, "--port", "9080", "--to-pod", "8080/UDP")
 	defer itest.TelepresenceOk(ctx, "leave", svc+"-"+s.AppNamespace())
-	require.Contains(stdout, "Using Deployment "+svc)
+	require.Contains(stdout, "Using deployment "+svc)
 	stdout = itest.TelepresenceOk(ctx, "list", "--namespace", s.AppNamespace(), "--intercepts")
 	require.Contains(stdout, svc+": intercepted")
 	itest.TelepresenceOk(ctx, "loglevel", "trace")
@@ -40,7 +40,7 @@ func (s *webhookSuite) Test_AutoInjectedAgent() {
 
 	stdout := itest.TelepresenceOk(ctx, "intercept", "--namespace", s.AppNamespace(), "--mount", "false", "echo-auto-inject", "--port", "9091")
 	defer itest.TelepresenceOk(ctx, "leave", "echo-auto-inject-"+s.AppNamespace())
-	require.Contains(stdout, "Using Deployment echo-auto-inject")
+	require.Contains(stdout, "Using deployment echo-auto-inject")
 	stdout = itest.TelepresenceOk(ctx, "list", "--namespace", s.AppNamespace(), "--intercepts")
 	require.Contains(stdout, "echo-auto-inject: intercepted")
 }
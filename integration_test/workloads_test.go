@@ -24,7 +24,7 @@ func (s *connectedSuite) successfulIntercept(tp, svc, port string) {
 	)
 
 	stdout := itest.TelepresenceOk(ctx, "intercept", "--namespace", s.AppNamespace(), "--mount", "false", "--port", port, svc)
-	require.Contains(stdout, "Using "+tp+" "+svc)
+	require.Contains(stdout, "Using "+strings.ToLower(tp)+" "+svc)
 	stdout = itest.TelepresenceOk(ctx, "list", "--namespace", s.AppNamespace(), "--intercepts")
 	require.Contains(stdout, svc+": intercepted")
 	require.NotContains(stdout, "Volume Mount Point")
@@ -1,7 +1,12 @@
 package agentconfig
 
 import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
 	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
 )
 
 // SpecMatchesIntercept answers the question if an InterceptSpec matches the given
@@ -42,3 +47,67 @@ func PortUniqueIntercepts(cn *Container) []*Intercept {
 	}
 	return ics
 }
+
+// FindIntercept finds the intercept configuration that matches the given InterceptSpec's service/service port
+func FindIntercept(ac *Sidecar, spec *manager.InterceptSpec) (foundCN *Container, foundIC *Intercept, err error) {
+	spi := PortIdentifier(spec.ServicePortIdentifier)
+	for _, cn := range ac.Containers {
+		for _, ic := range cn.Intercepts {
+			if !(spec.ServiceName == "" || spec.ServiceName == ic.ServiceName) {
+				continue
+			}
+			if !(spi == "" || IsInterceptFor(spi, ic)) {
+				continue
+			}
+			if foundIC == nil {
+				foundCN = cn
+				foundIC = ic
+				continue
+			}
+			var msg string
+			switch {
+			case spec.ServiceName == "" && spi == "":
+				msg = fmt.Sprintf("%s %s.%s has multiple interceptable service ports.\n"+
+					"Please specify the service and/or service port you want to intercept "+
+					"by passing the --service=<svc> and/or --port=<local:svcPortName> flag.",
+					ac.WorkloadKind, ac.WorkloadName, ac.Namespace)
+			case spec.ServiceName == "":
+				msg = fmt.Sprintf("%s %s.%s has multiple interceptable services with port %s.\n"+
+					"Please specify the service you want to intercept by passing the --service=<svc> flag.",
+					ac.WorkloadKind, ac.WorkloadName, ac.Namespace, spi)
+			case spi == "":
+				msg = fmt.Sprintf("%s %s.%s has multiple interceptable ports in service %s.\n"+
+					"Please specify the port you want to intercept by passing the --port=<local:svcPortName> flag.",
+					ac.WorkloadKind, ac.WorkloadName, ac.Namespace, spec.ServiceName)
+			default:
+				msg = fmt.Sprintf("%s %s.%s intercept config is broken. Service %s, port %s is declared more than once\n",
+					ac.WorkloadKind, ac.WorkloadName, ac.Namespace, spec.ServiceName, spi)
+			}
+			return nil, nil, errcat.User.New(msg)
+		}
+	}
+	if foundIC != nil {
+		return foundCN, foundIC, nil
+	}
+
+	ss := ""
+	if spec.ServiceName != "" {
+		if spi != "" {
+			ss = fmt.Sprintf(" matching service %s, port %s", spec.ServiceName, spi)
+		} else {
+			ss = fmt.Sprintf(" matching service %s", spec.ServiceName)
+		}
+	} else if spi != "" {
+		ss = fmt.Sprintf(" matching port %s", spi)
+	}
+	return nil, nil, errcat.User.Newf("%s %s.%s has no interceptable port%s", ac.WorkloadKind, ac.WorkloadName, ac.Namespace, ss)
+}
+
+// UnmarshalConfigMapEntry parses the given entry for the named workload in the ConfigMap of the given namespace.
+func UnmarshalConfigMapEntry(y string, name, namespace string) (*Sidecar, error) {
+	conf := Sidecar{}
+	if err := yaml.Unmarshal([]byte(y), &conf); err != nil {
+		return nil, fmt.Errorf("failed to parse entry for %s in ConfigMap %s.%s: %w", name, ConfigMap, namespace, err)
+	}
+	return &conf, nil
+}
@@ -12,53 +12,45 @@ func CommandsToRPC(cmds CommandGroups) *connector.CommandGroups {
 	for name, g := range cmds {
 		cmds := []*connector.CommandGroups_Command{}
 		for _, cmd := range g {
-			flags := []*connector.CommandGroups_Flag{}
-			cmd.Flags().VisitAll(func(f *pflag.Flag) {
-				flags = append(flags, &connector.CommandGroups_Flag{
-					Type:         f.Value.Type(),
-					Flag:         f.Name,
-					Help:         f.Usage,
-					Shorthand:    f.Shorthand,
-					DefaultValue: f.Value.String(),
-				})
-			})
-			cmds = append(cmds, &connector.CommandGroups_Command{
-				Name:      cmd.Use,
-				LongHelp:  cmd.Long,
-				ShortHelp: cmd.Short,
-				Flags:     flags,
-			})
+			cmds = append(cmds, commandToRPC(cmd))
 		}
 		groups[name] = &connector.CommandGroups_Commands{Commands: cmds}
 	}
 	return &connector.CommandGroups{CommandGroups: groups}
 }
 
+func commandToRPC(cmd *cobra.Command) *connector.CommandGroups_Command {
+	flags := []*connector.CommandGroups_Flag{}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		flags = append(flags, &connector.CommandGroups_Flag{
+			Type:         f.Value.Type(),
+			Flag:         f.Name,
+			Help:         f.Usage,
+			Shorthand:    f.Shorthand,
+			DefaultValue: f.Value.String(),
+		})
+	})
+	subCmds := []*connector.CommandGroups_Command{}
+	for _, subCmd := range cmd.Commands() {
+		subCmds = append(subCmds, commandToRPC(subCmd))
+	}
+	return &connector.CommandGroups_Command{
+		Name:        cmd.Use,
+		LongHelp:    cmd.Long,
+		ShortHelp:   cmd.Short,
+		Flags:       flags,
+		Subcommands: subCmds,
+	}
+}
+
 func RPCToCommands(remote *connector.CommandGroups, runner func(*cobra.Command, []string) error) (CommandGroups, error) {
 	groups := CommandGroups{}
 	for name, cmds := range remote.GetCommandGroups() {
 		commands := []*cobra.Command{}
 		for _, cmd := range cmds.GetCommands() {
-			cobraCmd := &cobra.Command{
-				Use:   cmd.GetName(),
-				Long:  cmd.GetLongHelp(),
-				Short: cmd.GetShortHelp(),
-				RunE:  runner,
-			}
-			for _, flag := range cmd.GetFlags() {
-				tp, err := TypeFromString(flag.GetType())
-				if err != nil {
-					return nil, err
-				}
-				val, err := tp.NewFlagValueFromPFlagString(flag.GetDefaultValue())
-				if err != nil {
-					return nil, err
-				}
-				if flag.GetShorthand() == "" {
-					cobraCmd.Flags().Var(val, flag.GetFlag(), flag.GetHelp())
-				} else {
-					cobraCmd.Flags().VarP(val, flag.GetFlag(), flag.GetShorthand(), flag.GetHelp())
-				}
+			cobraCmd, err := rpcToCommand(cmd, runner)
+			if err != nil {
+				return nil, err
 			}
 			commands = append(commands, cobraCmd)
 		}
@@ -66,3 +58,43 @@ func RPCToCommands(remote *connector.CommandGroups, runner func(*cobra.Command,
 	}
 	return groups, nil
 }
+
+// rpcToCommand returns a command that calls the given runner, or, when the command has subcommands, a command
+// that just groups its subcommands.
+func rpcToCommand(cmd *connector.CommandGroups_Command, runner func(*cobra.Command, []string) error) (*cobra.Command, error) {
+	cobraCmd := &cobra.Command{
+		Use:   cmd.GetName(),
+		Long:  cmd.GetLongHelp(),
+		Short: cmd.GetShortHelp(),
+	}
+	if len(cmd.GetSubcommands()) == 0 {
+		cobraCmd.RunE = runner
+	}
+	for _, flag := range cmd.GetFlags() {
+		tp, err := TypeFromString(flag.GetType())
+		if err != nil {
+			return nil, err
+		}
+		val, err := tp.NewFlagValueFromPFlagString(flag.GetDefaultValue())
+		if err != nil {
+			return nil, err
+		}
+		if flag.GetShorthand() == "" {
+			cobraCmd.Flags().Var(val, flag.GetFlag(), flag.GetHelp())
+		} else {
+			cobraCmd.Flags().VarP(val, flag.GetFlag(), flag.GetShorthand(), flag.GetHelp())
+		}
+		if flag.GetType() == "bool" {
+			// Allow a boolean flag without a value, e.g. "--force"
+			cobraCmd.Flags().Lookup(flag.GetFlag()).NoOptDefVal = "true"
+		}
+	}
+	for _, subCmd := range cmd.GetSubcommands() {
+		cobraSubCmd, err := rpcToCommand(subCmd, runner)
+		if err != nil {
+			return nil, err
+		}
+		cobraCmd.AddCommand(cobraSubCmd)
+	}
+	return cobraCmd, nil
+}
@@ -411,3 +411,36 @@ func TestToFromRPC(t *testing.T) { //nolint:gocognit,gocyclo // it's a long list
 		t.Error(err)
 	}
 }
+
+func TestToFromRPC_subcommands(t *testing.T) {
+	rCmd := &cobra.Command{Use: "parent", Short: "a parent"}
+	rSubCmd := &cobra.Command{
+		Use:  "child",
+		RunE: func(_ *cobra.Command, _ []string) error { return nil },
+	}
+	rSubCmd.Flags().Bool("force", false, "")
+	rCmd.AddCommand(rSubCmd)
+
+	var ran *cobra.Command
+	grp, err := RPCToCommands(CommandsToRPC(CommandGroups{"Test": []*cobra.Command{rCmd}}), func(cmd *cobra.Command, _ []string) error {
+		ran = cmd
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lCmd := grp["Test"][0]
+	if lCmd.Use != "parent" || lCmd.Runnable() {
+		t.Fatalf("expected a parent command that isn't runnable, got %q, runnable %t", lCmd.Use, lCmd.Runnable())
+	}
+	lCmd.SetArgs([]string{"child", "--force"})
+	if err = lCmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if ran == nil || ran.Name() != "child" {
+		t.Fatalf("expected subcommand child to run, got %v", ran)
+	}
+	if force, _ := ran.Flags().GetBool("force"); !force {
+		t.Error("expected flag force to be true")
+	}
+}
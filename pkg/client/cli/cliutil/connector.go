@@ -22,6 +22,7 @@ import (
 	"github.com/telepresenceio/telepresence/rpc/v2/connector"
 	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/logging"
 	"github.com/telepresenceio/telepresence/v2/pkg/proc"
 )
 
@@ -81,7 +82,8 @@ func launchConnectorDaemon(ctx context.Context, connectorDaemon string, maybeSta
 				if _, err = ensureAppUserConfigDir(ctx); err != nil {
 					return nil, err
 				}
-				if err = proc.StartInBackground(connectorDaemon, "connector-foreground"); err != nil {
+				args := append([]string{connectorDaemon, "connector-foreground"}, logging.GetOptions(ctx).Args()...)
+				if err = proc.StartInBackground(args...); err != nil {
 					return nil, fmt.Errorf("failed to launch the connector service: %w", err)
 				}
 				if err = client.WaitUntilSocketAppears("connector", client.ConnectorSocketName, 10*time.Second); err != nil {
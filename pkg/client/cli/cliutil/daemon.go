@@ -17,6 +17,7 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/logging"
 	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
 	"github.com/telepresenceio/telepresence/v2/pkg/proc"
 )
@@ -52,7 +53,8 @@ func launchDaemon(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	return proc.StartInBackgroundAsRoot(ctx, client.GetExe(), "daemon-foreground", logDir, configDir)
+	args := append([]string{client.GetExe(), "daemon-foreground", logDir, configDir}, logging.GetOptions(ctx).Args()...)
+	return proc.StartInBackgroundAsRoot(ctx, args...)
 }
 
 // WithNetwork (1) ensures that the daemon is running, (2) establishes a connection to it, and (3)
@@ -175,6 +177,36 @@ func Disconnect(ctx context.Context, quitUserDaemon, quitRootDaemon bool) (err e
 	return err
 }
 
+// KillDaemons quits the daemons in the same way as Disconnect does, then terminates any daemon process
+// that is still running, e.g. a root daemon that was orphaned, and finally removes the sockets that such
+// processes may leave behind. It is not an error if no daemon is running.
+func KillDaemons(ctx context.Context) error {
+	stdout, stderr := output.Structured(ctx)
+	if err := Disconnect(ctx, true, true); err != nil {
+		fmt.Fprintf(stderr, "Unable to quit the daemons gracefully: %v\n", err)
+	}
+	for _, d := range []struct {
+		name       string
+		subCommand string
+		socket     string
+		asRoot     bool
+	}{
+		{name: "user daemon", subCommand: "connector-foreground", socket: client.ConnectorSocketName},
+		{name: "root daemon", subCommand: "daemon-foreground", socket: client.DaemonSocketName, asRoot: true},
+	} {
+		if err := proc.TerminateAll(ctx, d.asRoot, d.subCommand); err != nil {
+			return fmt.Errorf("unable to terminate the %s: %w", d.name, err)
+		}
+		if err := client.WaitUntilSocketVanishes(d.name, d.socket, 2*time.Second); err != nil {
+			fmt.Fprintf(stdout, "Removing stale %s socket %s\n", d.name, d.socket)
+			if err = client.RemoveStaleSocket(ctx, d.socket, d.asRoot); err != nil {
+				return fmt.Errorf("unable to remove the %s socket: %w", d.name, err)
+			}
+		}
+	}
+	return nil
+}
+
 func ensureAppUserConfigDir(ctx context.Context) (string, error) {
 	configDir, err := filelocation.AppUserConfigDir(ctx)
 	if err != nil {
@@ -12,6 +12,7 @@ import (
 
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/cliutil"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/logging"
 )
 
 var help = `Telepresence can connect to a cluster and route all outbound traffic from your
@@ -56,6 +57,9 @@ func PerhapsLegacyCommands(cmd *cobra.Command, args []string) error {
 	// out here.
 	tp1Flags := []string{"--swap-deployment", "-s", "--run", "--run-shell", "--docker-run", "--help"}
 	for _, v := range args {
+		if v == healthFlag {
+			return nil
+		}
 		for _, flag := range tp1Flags {
 			if v == flag {
 				return nil
@@ -91,6 +95,47 @@ func RunSubcommands(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// healthFlag is the flag that makes "telepresence --health" an alias for "telepresence health".
+const healthFlag = "--health"
+
+// runRoot is the cobra.Command.RunE of the top level command. It runs the "health" command when the
+// --health flag is present, and otherwise behaves like RunSubcommands.
+func runRoot(cmd *cobra.Command, args []string) error {
+	for i, arg := range args {
+		if arg == healthFlag {
+			return runHealthAlias(cmd, append(args[:i:i], args[i+1:]...))
+		}
+	}
+	return RunSubcommands(cmd, args)
+}
+
+// runHealthAlias runs the "health" subcommand of the given root command using the given args. The root
+// command doesn't parse its flags, so they are parsed here by the subcommand, which means that the --health
+// flag can be combined with other flags in any order, e.g. "telepresence --output json --health".
+func runHealthAlias(root *cobra.Command, args []string) error {
+	hc, _, err := root.Find([]string{"health"})
+	if err != nil {
+		return err
+	}
+	hc.SetContext(root.Context())
+	if err = hc.ParseFlags(args); err != nil {
+		return hc.FlagErrorFunc()(hc, err)
+	}
+	args = hc.Flags().Args()
+	if err = hc.ValidateArgs(args); err != nil {
+		return err
+	}
+	if root.PersistentPreRun != nil {
+		root.PersistentPreRun(hc, args)
+	}
+	if hc.PreRunE != nil {
+		if err = hc.PreRunE(hc, args); err != nil {
+			return err
+		}
+	}
+	return hc.RunE(hc, args)
+}
+
 // Command returns the top level "telepresence" CLI command
 func Command(ctx context.Context) *cobra.Command {
 	rootCmd := &cobra.Command{
@@ -99,7 +144,7 @@ func Command(ctx context.Context) *cobra.Command {
 
 		Short:              "Connect your workstation to a Kubernetes cluster",
 		Long:               help,
-		RunE:               RunSubcommands,
+		RunE:               runRoot,
 		SilenceErrors:      true, // main() will handle it after .ExecuteContext() returns
 		SilenceUsage:       true, // our FlagErrorFunc will handle it
 		DisableFlagParsing: true, // Bc of the legacyCommand parsing, see legacy_command.go
@@ -118,10 +163,10 @@ func Command(ctx context.Context) *cobra.Command {
 
 	rootCmd.InitDefaultHelpCmd()
 	static := cliutil.CommandGroups{
-		"Session Commands": []*cobra.Command{connectCommand(), LoginCommand(), LogoutCommand(), LicenseCommand(), statusCommand(), quitCommand()},
+		"Session Commands": []*cobra.Command{connectCommand(), LoginCommand(), LogoutCommand(), LicenseCommand(), statusCommand(), healthCommand(), quitCommand()},
 		"Traffic Commands": []*cobra.Command{listCommand(), interceptCommand(ctx), leaveCommand(), previewCommand()},
 		"Debug Commands":   []*cobra.Command{loglevelCommand(), gatherLogsCommand()},
-		"Other Commands":   []*cobra.Command{versionCommand(), uninstallCommand(), dashboardCommand(), ClusterIdCommand(), genYAMLCommand(), vpnDiagCommand()},
+		"Other Commands":   []*cobra.Command{versionCommand(), configCommand(), uninstallCommand(), dashboardCommand(), ClusterIdCommand(), genYAMLCommand(), vpnDiagCommand()},
 	}
 	for name, cmds := range static {
 		if _, ok := groups[name]; !ok {
@@ -144,6 +189,13 @@ func Command(ctx context.Context) *cobra.Command {
 	for _, group := range globalFlagGroups {
 		rootCmd.PersistentFlags().AddFlagSet(group.Flags)
 	}
+	rootCmd.Flags().Bool(healthFlag[2:], false, `alias for the "health" command`)
+
+	// Apply the --log-level and --log-format flags to the CLI logger. They are also passed on to the
+	// daemons when they are launched.
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, _ []string) {
+		logging.ApplyOptions(cmd.Context(), logging.OptionsFromFlags(cmd.Flags()))
+	}
 	return rootCmd
 }
 
@@ -205,6 +257,7 @@ func initGlobalFlagGroups() {
 				"output", "default",
 				"set the output format, supported values are 'json' and 'default'",
 			)
+			logging.AddFlags(flags)
 			return flags
 		}(),
 	}}
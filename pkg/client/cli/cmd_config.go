@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"net"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+)
+
+func configCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the Telepresence configuration",
+	}
+	cmd.AddCommand(configViewCommand())
+	return cmd
+}
+
+func configViewCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:  "view",
+		Args: cobra.NoArgs,
+
+		Short: "View the effective configuration",
+		Long: `View the configuration that results from merging the built-in defaults with the system wide
+config.yml files and the config.yml in the user's config directory.
+
+All values are shown, including those that are equal to the built-in defaults. Command line flags,
+such as --namespace or --connect-timeout, take priority over the values shown here.`,
+		RunE: configView,
+	}
+}
+
+// effectiveConfig is the view of a client.Config that configView prints. Unlike the client.Config's own
+// marshalling, which omits values that are equal to the built-in defaults, it always includes every value.
+type effectiveConfig struct {
+	Timeouts struct {
+		AgentInstall          string `json:"agentInstall" yaml:"agentInstall"`
+		Apply                 string `json:"apply" yaml:"apply"`
+		ClusterConnect        string `json:"clusterConnect" yaml:"clusterConnect"`
+		EndpointDial          string `json:"endpointDial" yaml:"endpointDial"`
+		Helm                  string `json:"helm" yaml:"helm"`
+		Intercept             string `json:"intercept" yaml:"intercept"`
+		ProxyDial             string `json:"proxyDial" yaml:"proxyDial"`
+		RoundtripLatency      string `json:"roundtripLatency" yaml:"roundtripLatency"`
+		TrafficManagerAPI     string `json:"trafficManagerAPI" yaml:"trafficManagerAPI"`
+		TrafficManagerConnect string `json:"trafficManagerConnect" yaml:"trafficManagerConnect"`
+	} `json:"timeouts" yaml:"timeouts"`
+	LogLevels struct {
+		UserDaemon string `json:"userDaemon" yaml:"userDaemon"`
+		RootDaemon string `json:"rootDaemon" yaml:"rootDaemon"`
+	} `json:"logLevels" yaml:"logLevels"`
+	Images struct {
+		Registry        string `json:"registry" yaml:"registry"`
+		AgentImage      string `json:"agentImage" yaml:"agentImage"`
+		WebhookRegistry string `json:"webhookRegistry" yaml:"webhookRegistry"`
+	} `json:"images" yaml:"images"`
+	Cloud struct {
+		SkipLogin       bool   `json:"skipLogin" yaml:"skipLogin"`
+		RefreshMessages string `json:"refreshMessages" yaml:"refreshMessages"`
+		SystemaHost     string `json:"systemaHost" yaml:"systemaHost"`
+		SystemaPort     string `json:"systemaPort" yaml:"systemaPort"`
+	} `json:"cloud" yaml:"cloud"`
+	Grpc struct {
+		MaxReceiveSize string `json:"maxReceiveSize" yaml:"maxReceiveSize"`
+	} `json:"grpc" yaml:"grpc"`
+	TelepresenceAPI struct {
+		Port int `json:"port" yaml:"port"`
+	} `json:"telepresenceAPI" yaml:"telepresenceAPI"`
+	Daemons struct {
+		UserDaemonBinary string `json:"userDaemonBinary" yaml:"userDaemonBinary"`
+	} `json:"daemons" yaml:"daemons"`
+	Intercept struct {
+		AppProtocolStrategy string `json:"appProtocolStrategy" yaml:"appProtocolStrategy"`
+		DefaultPort         int    `json:"defaultPort" yaml:"defaultPort"`
+	} `json:"intercept" yaml:"intercept"`
+	Cluster struct {
+		DefaultNamespace string   `json:"defaultNamespace" yaml:"defaultNamespace"`
+		NeverProxy       []string `json:"neverProxy" yaml:"neverProxy"`
+	} `json:"cluster" yaml:"cluster"`
+}
+
+func newEffectiveConfig(ctx context.Context, cfg *client.Config) *effectiveConfig {
+	ec := &effectiveConfig{}
+	t := &cfg.Timeouts
+	ec.Timeouts.AgentInstall = t.PrivateAgentInstall.String()
+	ec.Timeouts.Apply = t.PrivateApply.String()
+	ec.Timeouts.ClusterConnect = t.PrivateClusterConnect.String()
+	ec.Timeouts.EndpointDial = t.PrivateEndpointDial.String()
+	ec.Timeouts.Helm = t.PrivateHelm.String()
+	ec.Timeouts.Intercept = t.PrivateIntercept.String()
+	ec.Timeouts.ProxyDial = t.PrivateProxyDial.String()
+	ec.Timeouts.RoundtripLatency = t.PrivateRoundtripLatency.String()
+	ec.Timeouts.TrafficManagerAPI = t.PrivateTrafficManagerAPI.String()
+	ec.Timeouts.TrafficManagerConnect = t.PrivateTrafficManagerConnect.String()
+
+	ec.LogLevels.UserDaemon = cfg.LogLevels.UserDaemon.String()
+	ec.LogLevels.RootDaemon = cfg.LogLevels.RootDaemon.String()
+
+	ec.Images.Registry = cfg.Images.Registry(ctx)
+	ec.Images.AgentImage = cfg.Images.AgentImage(ctx)
+	ec.Images.WebhookRegistry = cfg.Images.WebhookRegistry(ctx)
+
+	ec.Cloud.SkipLogin = cfg.Cloud.SkipLogin
+	ec.Cloud.RefreshMessages = cfg.Cloud.RefreshMessages.String()
+	ec.Cloud.SystemaHost = cfg.Cloud.SystemaHost
+	ec.Cloud.SystemaPort = cfg.Cloud.SystemaPort
+
+	if !cfg.Grpc.MaxReceiveSize.IsZero() {
+		ec.Grpc.MaxReceiveSize = cfg.Grpc.MaxReceiveSize.String()
+	}
+	ec.TelepresenceAPI.Port = cfg.TelepresenceAPI.Port
+	ec.Daemons.UserDaemonBinary = cfg.Daemons.UserDaemonBinary
+
+	ec.Intercept.AppProtocolStrategy = cfg.Intercept.AppProtocolStrategy.String()
+	ec.Intercept.DefaultPort = cfg.Intercept.DefaultPort
+
+	ec.Cluster.DefaultNamespace = cfg.Cluster.DefaultNamespace
+	ec.Cluster.NeverProxy = make([]string, len(cfg.Cluster.NeverProxy))
+	for i, sn := range cfg.Cluster.NeverProxy {
+		ec.Cluster.NeverProxy[i] = (*net.IPNet)(sn).String()
+	}
+	return ec
+}
+
+// configView prints the effective configuration in the same YAML format as the config.yml files, or as
+// JSON when --output=json is used.
+func configView(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	ec := newEffectiveConfig(ctx, client.GetConfig(ctx))
+	stdout := cmd.OutOrStdout()
+	if output.WantsJSONOutput(cmd.Flags()) {
+		streamer, ok := stdout.(output.StructuredStreamer)
+		if !ok {
+			panic("writer not output.StructuredStreamer")
+		}
+		streamer.StructuredStream(ec, nil)
+		return nil
+	}
+	data, err := yaml.Marshal(ec)
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(data)
+	return err
+}
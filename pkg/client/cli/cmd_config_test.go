@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+func TestConfigView(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	env, err := client.LoadEnv(ctx)
+	require.NoError(t, err)
+	ctx = client.WithEnv(ctx, env)
+	cfg := client.GetDefaultConfig()
+	cfg.Intercept.DefaultPort = 9080
+	cfg.Cluster.DefaultNamespace = "my-namespace"
+	ctx = client.WithConfig(ctx, &cfg)
+
+	cmd := configViewCommand()
+	out := &strings.Builder{}
+	cmd.SetOut(out)
+	cmd.SetContext(ctx)
+	require.NoError(t, configView(cmd, nil))
+	assert.Equal(t, `timeouts:
+    agentInstall: 2m0s
+    apply: 1m0s
+    clusterConnect: 20s
+    endpointDial: 3s
+    helm: 30s
+    intercept: 5s
+    proxyDial: 5s
+    roundtripLatency: 2s
+    trafficManagerAPI: 15s
+    trafficManagerConnect: 1m0s
+logLevels:
+    userDaemon: info
+    rootDaemon: info
+images:
+    registry: `+env.Registry+`
+    agentImage: ""
+    webhookRegistry: `+env.Registry+`
+cloud:
+    skipLogin: false
+    refreshMessages: 168h0m0s
+    systemaHost: app.getambassador.io
+    systemaPort: "443"
+grpc:
+    maxReceiveSize: ""
+telepresenceAPI:
+    port: 0
+daemons:
+    userDaemonBinary: ""
+intercept:
+    appProtocolStrategy: http2Probe
+    defaultPort: 9080
+cluster:
+    defaultNamespace: my-namespace
+    neverProxy: []
+`, out.String())
+}
@@ -34,15 +34,18 @@ type gatherLogsArgs struct {
 func gatherLogsCommand() *cobra.Command {
 	gl := &gatherLogsArgs{}
 	cmd := &cobra.Command{
-		Use:   "gather-logs",
-		Args:  cobra.NoArgs,
+		Use:   "gather-logs [<output.zip>]",
+		Args:  cobra.MaximumNArgs(1),
 		Short: "Gather logs from traffic-manager, traffic-agent, user and root daemons, and export them into a zip file.",
 		Long: `Gather logs from traffic-manager, traffic-agent, user and root daemons,
 and export them into a zip file. Useful if you are opening a Github issue or asking
-someone to help you debug Telepresence.`,
+someone to help you debug Telepresence.
+
+Kubeconfig credentials, bearer tokens, and other obvious secrets are redacted
+from the logs before they are written to the zip file.`,
 		Example: `Here are a few examples of how you can use this command:
 # Get all logs and export to a given file
-telepresence gather-logs -o /tmp/telepresence_logs.zip
+telepresence gather-logs /tmp/telepresence_logs.zip
 
 # Get all logs and pod yaml manifests for components in the kubernetes cluster
 telepresence gather-logs -o /tmp/telepresence_logs.zip --get-pod-yaml
@@ -60,7 +63,13 @@ telepresence gather-logs --traffic-manager=False --traffic-agents=echo-easy-6848
 telepresence gather-logs --daemons=None
 `,
 
-		RunE: func(cmd *cobra.Command, _ []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if gl.outputFile != "" && gl.outputFile != args[0] {
+					return errcat.User.New("the output file can be given either as an argument or using --output-file, but not both")
+				}
+				gl.outputFile = args[0]
+			}
 			return gl.gatherLogs(cmd.Context(), cmd)
 		},
 	}
@@ -193,13 +202,12 @@ func (gl *gatherLogsArgs) gatherLogs(ctx context.Context, cmd *cobra.Command) er
 
 	// Zip up all the files we've created in the zip directory and return that to the user
 	dirEntries, err := os.ReadDir(exportDir)
-	files := make([]string, len(dirEntries))
 	if err != nil {
 		return errcat.User.New(err)
 	}
-	for i, entry := range dirEntries {
+	files := make([]string, 0, len(dirEntries))
+	for _, entry := range dirEntries {
 		if entry.IsDir() {
-			files = files[:len(files)-1]
 			continue
 		}
 
@@ -210,7 +218,12 @@ func (gl *gatherLogsArgs) gatherLogs(ctx context.Context, cmd *cobra.Command) er
 				fmt.Fprintf(cmd.ErrOrStderr(), "error anonymizing %s: %s\n", fullFileName, err)
 			}
 		}
-		files[i] = fullFileName
+		// secrets must never end up in the zip file, so a file that can't be redacted is left out
+		if err := redactSecrets(fullFileName); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "error redacting %s, file excluded: %s\n", fullFileName, err)
+			continue
+		}
+		files = append(files, fullFileName)
 	}
 
 	if err := zipFiles(files, gl.outputFile); err != nil {
@@ -469,6 +482,50 @@ func (a *anonymizer) anonymizeLog(logFile string) error {
 	return nil
 }
 
+// secretPatterns match credentials that may end up in logs or pod yaml, e.g. when a kubeconfig is
+// logged or a request is traced. The first submatch of each pattern is retained and the rest of the
+// match is replaced with redactedSecret.
+var secretPatterns = []*regexp.Regexp{
+	// kubeconfig credentials, and key/value pairs in yaml, json, or log fields. The key must be a word of
+	// its own and the value must be on the same line, so that e.g. a "serviceAccountToken:" or "secret:"
+	// key that is followed by a nested yaml map is left alone.
+	regexp.MustCompile(`(?i)(\b(?:client-key-data|client-certificate-data|id-token|refresh-token|access-token|token|password|api[-_]?key|secret)["']?[ \t]*[:=][ \t]*["']?)[^\s"',}]+`),
+	// values of environment variables with secret-like names in pod yaml or json, e.g. a "value:" that
+	// follows "- name: DB_PASSWORD"
+	regexp.MustCompile(`(?im)(^[ \t]*-?[ \t]*name:[ \t]*["']?[\w.-]*` + secretEnvName + `[\w.-]*["']?[ \t]*\r?\n[ \t]*value:[ \t]*["']?)[^\s"']+`),
+	regexp.MustCompile(`(?i)("name"[ \t]*:[ \t]*"[\w.-]*` + secretEnvName + `[\w.-]*"[ \t]*,[ \t]*"value"[ \t]*:[ \t]*")[^"]+`),
+	// credentials in authorization headers
+	regexp.MustCompile(`(?i)(\bauthorization["']?[ \t]*[:=][ \t]*["']?(?:bearer|basic)[ \t]+)[a-z0-9\-._~+/]+=*`),
+	// JSON Web Tokens
+	regexp.MustCompile(`()eyJ[a-zA-Z0-9_-]+\.eyJ[a-zA-Z0-9_-]+\.[a-zA-Z0-9_-]+`),
+}
+
+const redactedSecret = "[REDACTED]"
+
+// secretEnvName matches the part of an environment variable name that makes its value a secret.
+const secretEnvName = `(?:password|passwd|secret|token|api_?key|credentials?|private_?key)`
+
+// redactSecrets replaces all secrets in the given file that match one of the secretPatterns
+// with redactedSecret. The file is only rewritten when something was redacted.
+func redactSecrets(logFile string) error {
+	content, err := os.ReadFile(logFile)
+	if err != nil {
+		return err
+	}
+	redacted := redactString(string(content))
+	if redacted == string(content) {
+		return nil
+	}
+	return os.WriteFile(logFile, []byte(redacted), 0o600)
+}
+
+func redactString(s string) string {
+	for _, p := range secretPatterns {
+		s = p.ReplaceAllString(s, "${1}"+redactedSecret)
+	}
+	return s
+}
+
 // getSignificantPodNames is a helper function that takes in a
 // pod's name and returns the significant subnames that we want
 // to anonymize.  It currently works for pods owned by StatefulSets,
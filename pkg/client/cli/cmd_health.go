@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/cliutil"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+)
+
+// healthPollInterval is the interval between polls of the user daemon while waiting for readiness.
+var healthPollInterval = 500 * time.Millisecond
+
+type healthComponent struct {
+	Name   string `json:"name"`
+	Ready  bool   `json:"ready"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type healthOutput struct {
+	Ready      bool              `json:"ready"`
+	Components []healthComponent `json:"components"`
+}
+
+func healthCommand() *cobra.Command {
+	var timeout time.Duration
+	cmd := &cobra.Command{
+		Use:  "health",
+		Args: cobra.NoArgs,
+
+		Short: "Report the readiness of the daemons, the cluster connection, the outbound proxy, and the intercepts",
+		Long: `Report the readiness of the daemons, the cluster connection, the outbound proxy, and the intercepts.
+The command exits with a non-zero status unless all of them are ready (with --output json, the
+"err" field is set instead). Use --timeout to wait for readiness.
+"telepresence --health" is an alias for this command.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ho, err := waitForHealth(cmd.Context(), timeout, getHealth)
+			if err != nil {
+				return err
+			}
+			if output.WantsJSONOutput(cmd.Flags()) {
+				streamer, ok := cmd.OutOrStdout().(output.StructuredStreamer)
+				if !ok {
+					panic("writer not output.StructuredStreamer")
+				}
+				streamer.StructuredStream(ho, healthError(ho, timeout))
+				return nil
+			}
+			ho.print(cmd.OutOrStdout())
+			return healthError(ho, timeout)
+		},
+	}
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, ``+
+		`Maximum time to wait for all components to become ready, e.g. "30s". By default, the readiness is `+
+		`reported without waiting`)
+	return cmd
+}
+
+// getHealth returns the health reported by the user daemon. A user daemon that isn't running is reported
+// as not ready.
+func getHealth(ctx context.Context) (*healthOutput, error) {
+	var ho *healthOutput
+	err := cliutil.WithStartedConnector(ctx, false, func(ctx context.Context, cc connector.ConnectorClient) error {
+		r, err := cc.Health(ctx, &empty.Empty{})
+		if err != nil {
+			if status.Code(err) == codes.Unimplemented {
+				return errcat.User.New("the user daemon does not support health reports, please quit and reconnect")
+			}
+			return err
+		}
+		ho = &healthOutput{Ready: r.Ready}
+		for _, c := range r.Components {
+			ho.Components = append(ho.Components, healthComponent{Name: c.Name, Ready: c.Ready, Reason: c.Reason})
+		}
+		return nil
+	})
+	if errors.Is(err, cliutil.ErrNoUserDaemon) {
+		return &healthOutput{Components: []healthComponent{{Name: "user-daemon", Reason: "not running"}}}, nil
+	}
+	return ho, err
+}
+
+// waitForHealth polls the health using the given getHealth function until all components are ready or the
+// timeout elapses, and returns the last health obtained.
+func waitForHealth(ctx context.Context, timeout time.Duration, getHealth func(context.Context) (*healthOutput, error)) (*healthOutput, error) {
+	ho, err := getHealth(ctx)
+	if err != nil || ho.Ready || timeout <= 0 {
+		return ho, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ho, nil
+		case <-ticker.C:
+		}
+		next, err := getHealth(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ho, nil
+			}
+			return nil, err
+		}
+		if ho = next; ho.Ready {
+			return ho, nil
+		}
+	}
+}
+
+// healthError returns nil when the given health is ready, and otherwise an error that causes a
+// non-zero exit status.
+func healthError(ho *healthOutput, timeout time.Duration) error {
+	if ho.Ready {
+		return nil
+	}
+	if timeout > 0 {
+		return errcat.User.Newf("not ready after %s", timeout)
+	}
+	return errcat.User.New("not ready")
+}
+
+func (ho *healthOutput) print(out io.Writer) {
+	if ho.Ready {
+		fmt.Fprintln(out, "Telepresence is ready")
+	} else {
+		fmt.Fprintln(out, "Telepresence is not ready")
+	}
+	w := 0
+	for _, c := range ho.Components {
+		if len(c.Name) > w {
+			w = len(c.Name)
+		}
+	}
+	for _, c := range ho.Components {
+		state := "ready"
+		if !c.Ready {
+			state = "not ready"
+			if c.Reason != "" {
+				state += ", " + c.Reason
+			}
+		}
+		fmt.Fprintf(out, "  %-*s: %s\n", w, c.Name, state)
+	}
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+)
+
+func notReady(reason string) *healthOutput {
+	return &healthOutput{Components: []healthComponent{
+		{Name: "user-daemon", Ready: true},
+		{Name: "outbound", Reason: reason},
+	}}
+}
+
+func TestWaitForHealth(t *testing.T) {
+	defer func(d time.Duration) { healthPollInterval = d }(healthPollInterval)
+	healthPollInterval = time.Millisecond
+	ready := &healthOutput{Ready: true, Components: []healthComponent{{Name: "user-daemon", Ready: true}}}
+
+	// healthSequence returns the given healths in order, and then repeats the last one.
+	healthSequence := func(healths ...*healthOutput) (func(context.Context) (*healthOutput, error), *int) {
+		calls := 0
+		return func(context.Context) (*healthOutput, error) {
+			ho := healths[len(healths)-1]
+			if calls < len(healths) {
+				ho = healths[calls]
+			}
+			calls++
+			return ho, nil
+		}, &calls
+	}
+
+	t.Run("no timeout checks once", func(t *testing.T) {
+		get, calls := healthSequence(notReady("starting"), ready)
+		ho, err := waitForHealth(dlog.NewTestContext(t, false), 0, get)
+		require.NoError(t, err)
+		assert.False(t, ho.Ready)
+		assert.Equal(t, 1, *calls)
+		assert.EqualError(t, healthError(ho, 0), "not ready")
+	})
+
+	t.Run("waits until ready", func(t *testing.T) {
+		get, calls := healthSequence(notReady("starting"), notReady("starting"), ready)
+		ho, err := waitForHealth(dlog.NewTestContext(t, false), 5*time.Second, get)
+		require.NoError(t, err)
+		assert.True(t, ho.Ready)
+		assert.Equal(t, 3, *calls)
+		assert.NoError(t, healthError(ho, 5*time.Second))
+	})
+
+	t.Run("times out", func(t *testing.T) {
+		get, _ := healthSequence(notReady("starting"), notReady("still starting"))
+		ho, err := waitForHealth(dlog.NewTestContext(t, false), 50*time.Millisecond, get)
+		require.NoError(t, err)
+		assert.False(t, ho.Ready)
+		assert.Equal(t, "still starting", ho.Components[1].Reason)
+		assert.EqualError(t, healthError(ho, 50*time.Millisecond), "not ready after 50ms")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := waitForHealth(dlog.NewTestContext(t, false), time.Second, func(context.Context) (*healthOutput, error) {
+			return nil, errors.New("boom")
+		})
+		assert.EqualError(t, err, "boom")
+	})
+}
+
+func TestHealthOutput_print(t *testing.T) {
+	ho := &healthOutput{Components: []healthComponent{
+		{Name: "user-daemon", Ready: true},
+		{Name: "outbound", Reason: "the network is not yet routed to the cluster"},
+		{Name: "intercept/echo", Ready: true},
+	}}
+	out := &strings.Builder{}
+	ho.print(out)
+	assert.Equal(t, `Telepresence is not ready
+  user-daemon   : ready
+  outbound      : not ready, the network is not yet routed to the cluster
+  intercept/echo: ready
+`, out.String())
+}
+
+func TestRunRoot_healthAlias(t *testing.T) {
+	// newRoot returns a root command that is set up like the one returned by Command, and a pointer to the
+	// flags that its "health" subcommand ran with.
+	newRoot := func() (*cobra.Command, *[]string) {
+		var ran []string
+		root := &cobra.Command{Use: "telepresence", Args: PerhapsLegacyCommands, RunE: runRoot, DisableFlagParsing: true}
+		root.PersistentFlags().String("output", "default", "")
+		root.Flags().Bool("health", false, "")
+		hc := &cobra.Command{Use: "health", Args: cobra.NoArgs, RunE: func(cmd *cobra.Command, _ []string) error {
+			ran = append(ran, "output="+cmd.Flag("output").Value.String(), "namespace="+cmd.Flag("namespace").Value.String())
+			return nil
+		}}
+		hc.Flags().StringP("namespace", "n", "", "")
+		root.AddCommand(hc)
+		root.SetOut(&strings.Builder{})
+		root.SetErr(&strings.Builder{})
+		return root, &ran
+	}
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"--health"}, []string{"output=default", "namespace="}},
+		{[]string{"--output", "json", "--health"}, []string{"output=json", "namespace="}},
+		{[]string{"--health", "--output=json"}, []string{"output=json", "namespace="}},
+		{[]string{"-n", "x", "--health"}, []string{"output=default", "namespace=x"}},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			root, ran := newRoot()
+			root.SetArgs(tt.args)
+			require.NoError(t, root.ExecuteContext(dlog.NewTestContext(t, false)))
+			assert.Equal(t, tt.want, *ran)
+		})
+	}
+
+	t.Run("rejects positional arguments", func(t *testing.T) {
+		root, ran := newRoot()
+		root.SetArgs([]string{"--health", "extra"})
+		assert.Error(t, root.ExecuteContext(dlog.NewTestContext(t, false)))
+		assert.Empty(t, *ran)
+	})
+}
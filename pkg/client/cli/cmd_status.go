@@ -7,6 +7,9 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
+	"os/signal"
+	"reflect"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -15,15 +18,24 @@ import (
 	"github.com/telepresenceio/telepresence/rpc/v2/connector"
 	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/cliutil"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
 	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
 )
 
 type statusInfo struct {
-	json bool
-	out  io.Writer
+	json  bool
+	watch bool
+	out   io.Writer
+
+	// skipUserInfoRefresh is set when watching, after the first status has been retrieved, to
+	// avoid a call to Ambassador Cloud for each poll.
+	skipUserInfoRefresh bool
 }
 
+// statusWatchInterval is the interval between polls of the daemons when the status is watched.
+var statusWatchInterval = time.Second
+
 type statusOutput struct {
 	DaemonStatus daemonStatus    `json:"root_daemon"`
 	UserDaemon   connectorStatus `json:"user_daemon"`
@@ -34,16 +46,19 @@ type daemonStatus struct {
 	Version           string           `json:"version,omitempty"`
 	APIVersion        int32            `json:"api_version,omitempty"`
 	DNS               *daemonStatusDNS `json:"dns,omitempty"`
+	RouterRunning     bool             `json:"router_running,omitempty"`
 	AlsoProxySubnets  []string         `json:"also_proxy_subnets,omitempty"`
 	NeverProxySubnets []string         `json:"never_proxy_subnets,omitempty"`
 }
 
 type daemonStatusDNS struct {
-	LocalIP         net.IP        `json:"local_ip,omitempty"`
-	RemoteIP        net.IP        `json:"remote_ip,omitempty"`
-	ExcludeSuffixes []string      `json:"exclude_suffixes,omitempty"`
-	IncludeSuffixes []string      `json:"include_suffixes,omitempty"`
-	LookupTimeout   time.Duration `json:"lookup_timeout_in_nanos,omitempty"`
+	LocalIP         net.IP            `json:"local_ip,omitempty"`
+	RemoteIP        net.IP            `json:"remote_ip,omitempty"`
+	ExcludeSuffixes []string          `json:"exclude_suffixes,omitempty"`
+	IncludeSuffixes []string          `json:"include_suffixes,omitempty"`
+	LookupTimeout   time.Duration     `json:"lookup_timeout_in_nanos,omitempty"`
+	SearchDomains   []string          `json:"search_domains,omitempty"`
+	Resolvers       map[string]string `json:"resolvers,omitempty"`
 }
 
 type connectorStatus struct {
@@ -57,6 +72,9 @@ type connectorStatus struct {
 	Error             string                         `json:"error,omitempty"`
 	KubernetesServer  string                         `json:"kubernetes_server,omitempty"`
 	KubernetesContext string                         `json:"kubernetes_context,omitempty"`
+	TokenExpiry       *time.Time                     `json:"token_expiry,omitempty"`
+	TokenRefreshError string                         `json:"token_refresh_error,omitempty"`
+	SessionExpiry     *time.Time                     `json:"session_expiry,omitempty"`
 	Intercepts        []connectStatusIntercept       `json:"intercepts,omitempty"`
 }
 
@@ -83,28 +101,95 @@ func statusCommand() *cobra.Command {
 	}
 	flags := cmd.Flags()
 	flags.BoolVarP(&s.json, "json", "j", false, "output as json object")
+	flags.BoolVarP(&s.watch, "watch", "w", false,
+		"reprint the status each time it changes, until interrupted. With --json or --output=json, one json object is printed per change")
 	return cmd
 }
 
 // status will retrieve connectivity status from the daemon and print it on stdout.
 func (s *statusInfo) status(cmd *cobra.Command, _ []string) error {
 	s.out = cmd.OutOrStdout()
-	ctx := cmd.Context()
+	if s.watch {
+		// An interrupt just ends the watch. Nothing is modified by this command, so there's nothing to clean up.
+		ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt)
+		defer cancel()
+		var streamer output.StructuredStreamer
+		if output.WantsJSONOutput(cmd.Flags()) {
+			streamer, _ = s.out.(output.StructuredStreamer)
+		}
+		return s.watchStatus(ctx, streamer, s.getStatus)
+	}
 
-	ds, err := s.daemonStatus(ctx)
+	so, err := s.getStatus(cmd.Context())
 	if err != nil {
 		return err
 	}
+	return s.print(so)
+}
+
+func (s *statusInfo) getStatus(ctx context.Context) (*statusOutput, error) {
+	ds, err := s.daemonStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	cs, err := s.connectorStatus(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return &statusOutput{DaemonStatus: *ds, UserDaemon: *cs}, nil
+}
+
+// watchStatus polls the status using the given getStatus function and prints it each time it differs from the
+// previously printed status. It returns when the given context is cancelled. When a streamer is given, each
+// status is streamed as one structured object.
+func (s *statusInfo) watchStatus(
+	ctx context.Context,
+	streamer output.StructuredStreamer,
+	getStatus func(context.Context) (*statusOutput, error),
+) error {
+	ticker := time.NewTicker(statusWatchInterval)
+	defer ticker.Stop()
+
+	var last *statusOutput
+	for {
+		so, err := getStatus(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		s.skipUserInfoRefresh = true
+		if last == nil || !reflect.DeepEqual(last, so) {
+			switch {
+			case streamer != nil:
+				streamer.StructuredStream(so, nil)
+			case s.json:
+				if err = s.printJSON(so); err != nil {
+					return err
+				}
+			default:
+				if last != nil {
+					s.println()
+				}
+				s.printText(so)
+			}
+			last = so
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
 	}
+}
 
+func (s *statusInfo) print(so *statusOutput) error {
 	if s.json {
-		return s.printJSON(ds, cs)
+		return s.printJSON(so)
 	}
-	s.printText(ds, cs)
+	s.printText(so)
 	return nil
 }
 
@@ -125,6 +210,7 @@ func (s *statusInfo) daemonStatus(ctx context.Context) (*daemonStatus, error) {
 		ds.Running = true
 		ds.Version = version.Version
 		ds.APIVersion = version.ApiVersion
+		ds.RouterRunning = status.RouterRunning
 		if obc := status.OutboundConfig; obc != nil {
 			ds.DNS = &daemonStatusDNS{}
 			dns := obc.Dns
@@ -136,6 +222,8 @@ func (s *statusInfo) daemonStatus(ctx context.Context) (*daemonStatus, error) {
 			ds.DNS.ExcludeSuffixes = dns.ExcludeSuffixes
 			ds.DNS.IncludeSuffixes = dns.IncludeSuffixes
 			ds.DNS.LookupTimeout = dns.LookupTimeout.AsDuration()
+			ds.DNS.SearchDomains = dns.SearchDomains
+			ds.DNS.Resolvers = dns.Resolvers
 			for _, subnet := range obc.AlsoProxySubnets {
 				ds.AlsoProxySubnets = append(ds.AlsoProxySubnets, iputil.IPNetFromRPC(subnet).String())
 			}
@@ -172,7 +260,7 @@ func (s *statusInfo) connectorStatus(ctx context.Context) (*connectorStatus, err
 		if !cliutil.HasLoggedIn(ctx) {
 			cs.AmbassadorCloud.Status = "Logged out"
 		} else {
-			userInfo, err := cliutil.GetCloudUserInfo(ctx, false, true)
+			userInfo, err := cliutil.GetCloudUserInfo(ctx, false, !s.skipUserInfoRefresh)
 			if err != nil {
 				cs.AmbassadorCloud.Status = "Login expired (or otherwise no-longer-operational)"
 			} else {
@@ -195,6 +283,10 @@ func (s *statusInfo) connectorStatus(ctx context.Context) (*connectorStatus, err
 		case connector.ConnectInfo_DISCONNECTED:
 			cs.Status = "Not connected"
 			return nil
+		case connector.ConnectInfo_RECONNECTING:
+			cs.Status = "Reconnecting, connection to the cluster was lost"
+			cs.Error = status.ErrorText
+			return nil
 		case connector.ConnectInfo_CLUSTER_FAILED:
 			cs.Status = "Not connected, error talking to cluster"
 			cs.Error = status.ErrorText
@@ -206,6 +298,15 @@ func (s *statusInfo) connectorStatus(ctx context.Context) (*connectorStatus, err
 		}
 		cs.KubernetesServer = status.ClusterServer
 		cs.KubernetesContext = status.ClusterContext
+		if te := status.TokenExpiry; te != nil {
+			t := te.AsTime().Local()
+			cs.TokenExpiry = &t
+		}
+		cs.TokenRefreshError = status.TokenRefreshError
+		if se := status.SessionExpiry; se != nil {
+			t := se.AsTime().Local()
+			cs.SessionExpiry = &t
+		}
 		for _, icept := range status.GetIntercepts().GetIntercepts() {
 			cs.Intercepts = append(cs.Intercepts, connectStatusIntercept{
 				Name:   icept.Spec.Name,
@@ -224,21 +325,18 @@ func (s *statusInfo) connectorStatus(ctx context.Context) (*connectorStatus, err
 	return cs, nil
 }
 
-func (s *statusInfo) printJSON(ds *daemonStatus, cs *connectorStatus) error {
-	output, err := json.Marshal(statusOutput{
-		DaemonStatus: *ds,
-		UserDaemon:   *cs,
-	})
+func (s *statusInfo) printJSON(so *statusOutput) error {
+	data, err := json.Marshal(so)
 	if err != nil {
 		return err
 	}
-	s.println(string(output))
+	s.println(string(data))
 	return nil
 }
 
-func (s *statusInfo) printText(ds *daemonStatus, cs *connectorStatus) {
-	s.printDaemonText(ds)
-	s.printConnectorText(cs)
+func (s *statusInfo) printText(so *statusOutput) {
+	s.printDaemonText(&so.DaemonStatus)
+	s.printConnectorText(&so.UserDaemon)
 }
 
 func (s *statusInfo) printDaemonText(ds *daemonStatus) {
@@ -254,6 +352,17 @@ func (s *statusInfo) printDaemonText(ds *daemonStatus) {
 			s.printf("    Exclude suffixes: %v\n", ds.DNS.ExcludeSuffixes)
 			s.printf("    Include suffixes: %v\n", ds.DNS.IncludeSuffixes)
 			s.printf("    Timeout         : %v\n", ds.DNS.LookupTimeout)
+			if len(ds.DNS.SearchDomains) > 0 {
+				s.printf("    Search domains  : %v\n", ds.DNS.SearchDomains)
+			}
+			if len(ds.DNS.Resolvers) > 0 {
+				s.printf("    Resolvers       : %v\n", ds.DNS.Resolvers)
+			}
+			if ds.RouterRunning {
+				s.printf("  Router     : running, proxying TCP and UDP\n")
+			} else {
+				s.printf("  Router     : not running\n")
+			}
 			s.printf("  Also Proxy : (%d subnets)\n", len(ds.AlsoProxySubnets))
 			for _, subnet := range ds.AlsoProxySubnets {
 				s.printf("    - %s\n", subnet)
@@ -285,6 +394,15 @@ func (s *statusInfo) printConnectorText(cs *connectorStatus) {
 		}
 		s.printf("  Kubernetes server : %s\n", cs.KubernetesServer)
 		s.printf("  Kubernetes context: %s\n", cs.KubernetesContext)
+		if cs.TokenExpiry != nil {
+			s.printf("  Token expiry      : %s\n", cs.TokenExpiry.Format(time.RFC1123))
+		}
+		if cs.TokenRefreshError != "" {
+			s.printf("  Token refresh     : failed: %s\n", cs.TokenRefreshError)
+		}
+		if cs.SessionExpiry != nil {
+			s.printf("  Session expiry    : %s\n", cs.SessionExpiry.Format(time.RFC1123))
+		}
 		s.printf("  Intercepts        : %d total\n", len(cs.Intercepts))
 		for _, intercept := range cs.Intercepts {
 			s.printf("    %s: %s\n", intercept.Name, intercept.Client)
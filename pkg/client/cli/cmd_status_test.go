@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+)
+
+// statusSequence returns a getStatus function that returns the given statuses in order, and then cancels the
+// given context.
+func statusSequence(cancel context.CancelFunc, statuses ...*statusOutput) func(context.Context) (*statusOutput, error) {
+	i := 0
+	return func(ctx context.Context) (*statusOutput, error) {
+		if i >= len(statuses) {
+			cancel()
+			return nil, ctx.Err()
+		}
+		so := statuses[i]
+		i++
+		return so, nil
+	}
+}
+
+func intercepted(names ...string) *statusOutput {
+	so := &statusOutput{
+		DaemonStatus: daemonStatus{Running: true, Version: "v2.7.0"},
+		UserDaemon:   connectorStatus{Running: true, Version: "v2.7.0", Status: "Connected"},
+	}
+	for _, name := range names {
+		so.UserDaemon.Intercepts = append(so.UserDaemon.Intercepts, connectStatusIntercept{Name: name, Client: "me"})
+	}
+	return so
+}
+
+func TestWatchStatus(t *testing.T) {
+	defer func(iv time.Duration) { statusWatchInterval = iv }(statusWatchInterval)
+	statusWatchInterval = time.Millisecond
+
+	t.Run("json prints once per change", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+		defer cancel()
+		out := &strings.Builder{}
+		s := &statusInfo{json: true, watch: true, out: out}
+		err := s.watchStatus(ctx, nil, statusSequence(cancel, intercepted(), intercepted(), intercepted("a"), intercepted("a"), intercepted()))
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 3)
+		var interceptCounts []int
+		for _, line := range lines {
+			var so statusOutput
+			require.NoError(t, json.Unmarshal([]byte(line), &so))
+			interceptCounts = append(interceptCounts, len(so.UserDaemon.Intercepts))
+		}
+		assert.Equal(t, []int{0, 1, 0}, interceptCounts)
+	})
+
+	t.Run("text reuses one-shot rendering", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+		defer cancel()
+		out := &strings.Builder{}
+		s := &statusInfo{watch: true, out: out}
+		require.NoError(t, s.watchStatus(ctx, nil, statusSequence(cancel, intercepted(), intercepted("a"))))
+
+		expected := &strings.Builder{}
+		s.out = expected
+		s.printText(intercepted())
+		s.println()
+		s.printText(intercepted("a"))
+		assert.Equal(t, expected.String(), out.String())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ctx := dlog.NewTestContext(t, false)
+		s := &statusInfo{watch: true, out: &strings.Builder{}}
+		err := s.watchStatus(ctx, nil, func(context.Context) (*statusOutput, error) {
+			return nil, errors.New("boom")
+		})
+		assert.EqualError(t, err, "boom")
+	})
+}
+
+func TestPrintDaemonText_routerRunning(t *testing.T) {
+	out := &strings.Builder{}
+	s := &statusInfo{out: out}
+	s.printDaemonText(&daemonStatus{Running: true, DNS: &daemonStatusDNS{}, RouterRunning: true})
+	assert.Contains(t, out.String(), "  Router     : running, proxying TCP and UDP\n")
+
+	out.Reset()
+	s.printDaemonText(&daemonStatus{Running: true, DNS: &daemonStatusDNS{}})
+	assert.Contains(t, out.String(), "  Router     : not running\n")
+}
@@ -3,7 +3,9 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 
+	"github.com/blang/semver"
 	"github.com/spf13/cobra"
 	empty "google.golang.org/protobuf/types/known/emptypb"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
 	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/cliutil"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
 )
 
 func versionCommand() *cobra.Command {
@@ -25,40 +28,103 @@ func versionCommand() *cobra.Command {
 	}
 }
 
-// printVersion requests version info from the daemon and prints both client and daemon version.
-func printVersion(cmd *cobra.Command, _ []string) error {
-	fmt.Fprintf(cmd.OutOrStdout(), "Client: %s\n",
-		client.DisplayVersion())
+// versionInfo is the version of one of the telepresence executables.
+type versionInfo struct {
+	Version    string   `json:"version"`
+	Major      uint64   `json:"major"`
+	Minor      uint64   `json:"minor"`
+	Patch      uint64   `json:"patch"`
+	Pre        []string `json:"pre,omitempty"`
+	Build      []string `json:"build,omitempty"`
+	Source     string   `json:"source,omitempty"`
+	APIVersion int32    `json:"api_version,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
 
-	var retErr error
+// versionOutput is the output of the version command. A daemon that isn't running is omitted.
+type versionOutput struct {
+	Client     *versionInfo `json:"client"`
+	RootDaemon *versionInfo `json:"root_daemon,omitempty"`
+	UserDaemon *versionInfo `json:"user_daemon,omitempty"`
+}
 
-	version, err := daemonVersion(cmd.Context())
-	switch {
-	case err == nil:
-		fmt.Fprintf(cmd.OutOrStdout(), "Root Daemon: %s (api v%d)\n",
-			version.Version, version.ApiVersion)
-	case err == cliutil.ErrNoNetwork:
-		fmt.Fprintf(cmd.OutOrStdout(), "Root Daemon: not running\n")
-	default:
-		fmt.Fprintf(cmd.OutOrStdout(), "Root Daemon: error: %v\n", err)
-		retErr = err
+func newVersionInfo(v string, sv semver.Version) *versionInfo {
+	vi := &versionInfo{
+		Version: v,
+		Major:   sv.Major,
+		Minor:   sv.Minor,
+		Patch:   sv.Patch,
+		Build:   sv.Build,
+	}
+	for _, pre := range sv.Pre {
+		vi.Pre = append(vi.Pre, pre.String())
 	}
+	return vi
+}
 
-	version, err = connectorVersion(cmd.Context())
+// daemonVersionInfo returns the versionInfo for a daemon version obtained using the given error, or nil
+// if the daemon isn't running.
+func daemonVersionInfo(version *common.VersionInfo, err, notRunning error) *versionInfo {
 	switch {
 	case err == nil:
-		fmt.Fprintf(cmd.OutOrStdout(), "User Daemon: %s (api v%d)\n",
-			version.Version, version.ApiVersion)
-	case err == cliutil.ErrNoUserDaemon:
-		fmt.Fprintf(cmd.OutOrStdout(), "User Daemon: not running\n")
+		// A daemon built without a semver version has no version components
+		sv, _ := semver.ParseTolerant(version.Version)
+		vi := newVersionInfo(version.Version, sv)
+		vi.APIVersion = version.ApiVersion
+		return vi
+	case err == notRunning:
+		return nil
 	default:
-		fmt.Fprintf(cmd.OutOrStdout(), "User Daemon: error: %v\n", err)
+		return &versionInfo{Error: err.Error()}
+	}
+}
+
+// printVersion requests version info from the daemon and prints both client and daemon version.
+func printVersion(cmd *cobra.Command, _ []string) error {
+	ctx := cmd.Context()
+	clientVersion := newVersionInfo(client.Version(), client.Semver())
+	clientVersion.Source = client.VersionSource()
+	vo := &versionOutput{Client: clientVersion}
+
+	var retErr error
+	version, err := daemonVersion(ctx)
+	vo.RootDaemon = daemonVersionInfo(version, err, cliutil.ErrNoNetwork)
+	if vo.RootDaemon != nil && vo.RootDaemon.Error != "" {
+		retErr = err
+	}
+	version, err = connectorVersion(ctx)
+	vo.UserDaemon = daemonVersionInfo(version, err, cliutil.ErrNoUserDaemon)
+	if vo.UserDaemon != nil && vo.UserDaemon.Error != "" {
 		retErr = err
 	}
 
+	stdout := cmd.OutOrStdout()
+	if output.WantsJSONOutput(cmd.Flags()) {
+		streamer, ok := stdout.(output.StructuredStreamer)
+		if !ok {
+			panic("writer not output.StructuredStreamer")
+		}
+		streamer.StructuredStream(vo, retErr)
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "Client: %s\n", client.DisplayVersion())
+	printDaemonVersion(stdout, "Root Daemon", vo.RootDaemon)
+	printDaemonVersion(stdout, "User Daemon", vo.UserDaemon)
 	return retErr
 }
 
+func printDaemonVersion(out io.Writer, name string, vi *versionInfo) {
+	switch {
+	case vi == nil:
+		fmt.Fprintf(out, "%s: not running\n", name)
+	case vi.Error != "":
+		fmt.Fprintf(out, "%s: error: %s\n", name, vi.Error)
+	default:
+		fmt.Fprintf(out, "%s: %s (api v%d)\n", name, vi.Version, vi.APIVersion)
+	}
+}
+
 func daemonVersion(ctx context.Context) (*common.VersionInfo, error) {
 	var version *common.VersionInfo
 	err := cliutil.WithStartedNetwork(ctx, func(ctx context.Context, daemonClient daemon.DaemonClient) error {
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/cliutil"
+)
+
+func Test_versionInfo(t *testing.T) {
+	vi := newVersionInfo("v2.8.0-rc.1+abc", semver.MustParse("2.8.0-rc.1+abc"))
+	vi.Source = "ldflags"
+	data, err := json.Marshal(vi)
+	require.NoError(t, err)
+	assert.JSONEq(t,
+		`{"version":"v2.8.0-rc.1+abc","major":2,"minor":8,"patch":0,"pre":["rc","1"],"build":["abc"],"source":"ldflags"}`,
+		string(data))
+
+	vi = daemonVersionInfo(&common.VersionInfo{Version: "v2.7.6", ApiVersion: 3}, nil, cliutil.ErrNoNetwork)
+	assert.Equal(t, &versionInfo{Version: "v2.7.6", Major: 2, Minor: 7, Patch: 6, APIVersion: 3}, vi)
+
+	vi = daemonVersionInfo(&common.VersionInfo{Version: "(devel)", ApiVersion: 3}, nil, cliutil.ErrNoNetwork)
+	assert.Equal(t, &versionInfo{Version: "(devel)", APIVersion: 3}, vi)
+
+	assert.Nil(t, daemonVersionInfo(nil, cliutil.ErrNoNetwork, cliutil.ErrNoNetwork))
+	assert.Equal(t, &versionInfo{Error: "boom"}, daemonVersionInfo(nil, errors.New("boom"), cliutil.ErrNoNetwork))
+}
@@ -15,9 +15,12 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/blang/semver"
 	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 	empty "google.golang.org/protobuf/types/known/emptypb"
 	core "k8s.io/api/core/v1"
 
@@ -32,18 +35,21 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cache"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/cliutil"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/extensions"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/output"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
 	"github.com/telepresenceio/telepresence/v2/pkg/proc"
+	"github.com/telepresenceio/telepresence/v2/pkg/throttle"
 )
 
 type interceptArgs struct {
-	name        string // Args[0] || `${Args[0]}-${--namespace}` // which depends on a combinationof --workload and --namespace
-	agentName   string // --workload || Args[0] // only valid if !localOnly
-	namespace   string // --namespace
-	port        string // --port // only valid if !localOnly
-	serviceName string // --service // only valid if !localOnly
-	localOnly   bool   // --local-only
+	name        string   // Args[0] || `${Args[0]}-${--namespace}` // which depends on a combinationof --workload and --namespace
+	agentName   string   // --workload || Args[0] // only valid if !localOnly
+	namespace   string   // --namespace
+	port        string   // --port // only valid if !localOnly
+	extraPorts  []string // repeated --port // only valid if !localOnly
+	serviceName string   // --service // only valid if !localOnly
+	localOnly   bool     // --local-only
 
 	previewEnabled bool                 // --preview-url // only valid if !localOnly
 	previewSpec    *manager.PreviewSpec // --preview-url-* // only valid if !localOnly
@@ -67,6 +73,14 @@ type interceptArgs struct {
 	ingressPort int32
 	ingressTLS  bool
 	ingressL5   string
+
+	interceptTimeout    time.Duration // --intercept-timeout
+	agentInstallTimeout time.Duration // --agent-install-timeout
+
+	dryRun bool // --dry-run
+
+	batch    bool            // --batch
+	throttle throttle.Config // --install-concurrency, --install-rate // only valid if batch
 }
 
 // safeCobraCommand is more-or-less a subset of *cobra.Command, with less stuff exposed so I don't
@@ -115,15 +129,20 @@ func interceptCommand(ctx context.Context) *cobra.Command {
 		Short:    "Intercept a service",
 		PreRunE:  updateCheckIfDue,
 		PostRunE: raiseCloudMessage,
+
+		ValidArgsFunction: completeInterceptableWorkloads,
 	}
 	args := interceptArgs{}
 	flags := cmd.Flags()
 
-	flags.StringVarP(&args.agentName, "workload", "w", "", "Name of workload (Deployment, ReplicaSet) to intercept, if different from <name>")
-	flags.StringVarP(&args.port, "port", "p", strconv.Itoa(client.GetConfig(ctx).Intercept.DefaultPort), ``+
+	flags.StringVarP(&args.agentName, "workload", "w", "", "Name of workload (Deployment, ReplicaSet, StatefulSet) to intercept, if different from <name>")
+	var ports []string
+	flags.StringArrayVarP(&ports, "port", "p", []string{strconv.Itoa(client.GetConfig(ctx).Intercept.DefaultPort)}, ``+
 		`Local port to forward to. If intercepting a service with multiple ports, `+
-		`use <local port>:<svcPortIdentifier>, where the identifier is the port name or port number. `+
-		`With --docker-run, use <local port>:<container port> or <local port>:<container port>:<svcPortIdentifier>.`,
+		`use <local port>:<svcPortIdentifier>, where the identifier is the port name or port number. The name of `+
+		`a container port in the workload's pod template can be used too. `+
+		`With --docker-run, use <local port>:<container port> or <local port>:<container port>:<svcPortIdentifier>. `+
+		`Repeat the flag to intercept several ports of the same workload, e.g. --port 8080:http --port 9090:grpc.`,
 	)
 
 	flags.StringVar(&args.serviceName, "service", "", "Name of service to intercept. If not provided, we will try to auto-detect one")
@@ -161,6 +180,31 @@ func interceptCommand(ctx context.Context) *cobra.Command {
 		`The volume mount point in docker. Defaults to same as "--mount"`)
 
 	flags.StringVarP(&args.namespace, "namespace", "n", "", "If present, the namespace scope for this CLI request")
+	flags.DurationVar(&args.interceptTimeout, "intercept-timeout", 0, ``+
+		`Maximum time to wait for the intercept to become active once the traffic-agent is in place. `+
+		`Defaults to the timeouts.intercept setting of the config.yml`)
+	flags.DurationVar(&args.agentInstallTimeout, "agent-install-timeout", 0, ``+
+		`Maximum time to wait for the traffic-agent to be installed, e.g. when the workload's image pulls are slow. `+
+		`Defaults to the time that the traffic-manager waits, or to the timeouts.agentInstall setting of the `+
+		`config.yml when using an older traffic-manager`)
+
+	flags.BoolVar(&args.dryRun, "dry-run", false, ``+
+		`Validate the intercept and print what it would do, e.g. which workload and service port it targets and `+
+		`whether a traffic-agent will be injected, without changing anything in the cluster or running a command`)
+
+	flags.BoolVar(&args.batch, "batch", false, ``+
+		`Intercept several workloads at once. All arguments are taken to be names of workloads, and each one is `+
+		`intercepted using the given flags. Workloads that need a traffic-agent are injected at the pace given by `+
+		`--install-concurrency and --install-rate`)
+	var rate string
+	flags.IntVar(&args.throttle.Concurrency, "install-concurrency", 1, ``+
+		`Maximum number of traffic-agents that are injected concurrently when using --batch. Zero means no limit`)
+	flags.StringVar(&rate, "install-rate", "", ``+
+		`Maximum rate at which traffic-agent injections are started when using --batch, e.g. "2/s" or "10/m". `+
+		`Empty means no limit`)
+
+	_ = cmd.RegisterFlagCompletionFunc("workload", completeWorkloadFlag)
+	_ = cmd.RegisterFlagCompletionFunc("namespace", completeNamespaces)
 
 	flags.StringVar(&args.ingressHost, "ingress-host", "", "If this flag is set, the ingress dialogue will be skipped,"+
 		" and this value will be used as the ingress hostname.")
@@ -187,6 +231,23 @@ func interceptCommand(ctx context.Context) *cobra.Command {
 		if err != nil {
 			return err
 		}
+		if len(ports) > 0 {
+			args.port = ports[0]
+			args.extraPorts = ports[1:]
+		}
+		args.mountSet = cmd.Flag("mount").Changed
+		if args.batch {
+			if args.throttle.Rate, err = throttle.ParseRate(rate); err != nil {
+				return errcat.User.New(err)
+			}
+			if err = validateBatchArgs(cmd, &args, positional); err != nil {
+				return err
+			}
+			return interceptBatch(cmd, args, positional)
+		}
+		if cmd.Flag("install-concurrency").Changed || cmd.Flag("install-rate").Changed {
+			return errcat.User.New("--install-concurrency and --install-rate can only be used together with --batch")
+		}
 		args.name = positional[0]
 		args.cmdline = positional[1:]
 		switch args.localOnly { // a switch instead of an if/else to get gocritic to not suggest "else if"
@@ -216,8 +277,10 @@ func interceptCommand(ctx context.Context) *cobra.Command {
 				}
 			}
 		}
-		args.mountSet = cmd.Flag("mount").Changed
 		if args.dockerRun {
+			if len(args.extraPorts) > 0 {
+				return errcat.User.New("--docker-run cannot be combined with a repeated --port")
+			}
 			if err := validateDockerArgs(args.cmdline); err != nil {
 				return err
 			}
@@ -238,10 +301,33 @@ func leaveCommand() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return removeIntercept(cmd.Context(), strings.TrimSpace(args[0]))
 		},
+		ValidArgsFunction: completeInterceptNames,
 	}
 }
 
 func intercept(cmd *cobra.Command, args interceptArgs) error {
+	if args.dryRun {
+		// connect without installing the traffic-manager, print the plan, and then disconnect unless a
+		// connection already existed
+		return withConnectorNoInstall(cmd, false, func(ctx context.Context, cs *connectorState) error {
+			is := newInterceptState(ctx, safeCobraCommandImpl{cmd}, args, cs, nil)
+			defer is.scout.Close()
+			plan, err := is.dryRun(ctx)
+			if err != nil {
+				return err
+			}
+			if output.WantsJSONOutput(cmd.Flags()) {
+				streamer, ok := cmd.OutOrStdout().(output.StructuredStreamer)
+				if !ok {
+					panic("writer not output.StructuredStreamer")
+				}
+				streamer.StructuredStream(plan, nil)
+			} else {
+				plan.print(cmd.OutOrStdout())
+			}
+			return nil
+		})
+	}
 	if len(args.cmdline) == 0 && !args.dockerRun {
 		// start and retain the intercept
 		return withConnector(cmd, true, nil, func(ctx context.Context, cs *connectorState) error {
@@ -475,6 +561,12 @@ func (is *interceptState) createRequest(ctx context.Context) (*connector.CreateI
 		Namespace: is.args.namespace,
 	}
 	ir := &connector.CreateInterceptRequest{Spec: spec}
+	if is.args.interceptTimeout > 0 {
+		ir.InterceptTimeout = durationpb.New(is.args.interceptTimeout)
+	}
+	if is.args.agentInstallTimeout > 0 {
+		ir.AgentInstallTimeout = durationpb.New(is.args.agentInstallTimeout)
+	}
 
 	if is.args.agentName == "" {
 		// local-only
@@ -495,18 +587,21 @@ func (is *interceptState) createRequest(ctx context.Context) (*connector.CreateI
 		return nil, err
 	}
 	spec.TargetPort = int32(is.localPort)
+	if err = is.validateExtraPorts(spec.ServicePortIdentifier); err != nil {
+		return nil, err
+	}
 
 	doMount := false
-	if err = checkMountCapability(ctx); err == nil {
+	mountErr := checkMountCapability(ctx)
+	if mountErr == nil {
 		if ir.MountPoint, doMount, err = is.getMountPoint(); err != nil {
 			return nil, err
 		}
 	} else if is.args.mountSet {
-		var boolErr error
-		doMount, boolErr = strconv.ParseBool(is.args.mount)
-		if boolErr != nil || doMount {
-			// not --mount=false, so refuse.
-			return nil, errcat.User.Newf("remote volume mounts are disabled: %w", err)
+		wantMount, boolErr := strconv.ParseBool(is.args.mount)
+		if boolErr != nil || wantMount {
+			// not --mount=false, so tell the user why the intercept won't have any mounts.
+			fmt.Fprintf(is.cmd.ErrOrStderr(), "Warning: remote volume mounts are disabled: %v. The intercept will be created without mounts.\n", mountErr)
 		}
 	}
 
@@ -527,6 +622,9 @@ func (is *interceptState) createRequest(ctx context.Context) (*connector.CreateI
 			return nil, errcat.User.New("--docker-mount must be used together with --docker-run")
 		}
 		if !doMount {
+			if mountErr != nil {
+				return nil, errcat.User.Newf("--docker-mount cannot be used when remote volume mounts are disabled: %w", mountErr)
+			}
 			return nil, errcat.User.New("--docker-mount cannot be used with --mount=false")
 		}
 	}
@@ -540,6 +638,89 @@ func (is *interceptState) createRequest(ctx context.Context) (*connector.CreateI
 	return ir, nil
 }
 
+// validateExtraPorts checks that the ports given using a repeated --port are unique and that they, and
+// the first port, all identify the service port to intercept.
+func (is *interceptState) validateExtraPorts(svcPortID string) error {
+	if len(is.args.extraPorts) == 0 {
+		return nil
+	}
+	if svcPortID == "" {
+		return errcat.User.New("when --port is repeated, each port must be of the format --port <local-port>:<svcPortIdentifier>")
+	}
+	localPorts := map[uint16]struct{}{is.localPort: {}}
+	svcPortIDs := map[string]struct{}{svcPortID: {}}
+	for _, portSpec := range is.args.extraPorts {
+		local, _, id, err := parsePort(portSpec, false)
+		if err != nil {
+			return err
+		}
+		if id == "" {
+			return errcat.User.New("when --port is repeated, each port must be of the format --port <local-port>:<svcPortIdentifier>")
+		}
+		if _, ok := localPorts[local]; ok {
+			return errcat.User.Newf("local port %d is used more than once", local)
+		}
+		if _, ok := svcPortIDs[id]; ok {
+			return errcat.User.Newf("service port %s is intercepted more than once", id)
+		}
+		localPorts[local] = struct{}{}
+		svcPortIDs[id] = struct{}{}
+	}
+	return nil
+}
+
+// companionInterceptName returns the name of the additional intercept that is created for the
+// given local port when --port is repeated.
+func companionInterceptName(name string, localPort uint16) string {
+	return name + "-" + strconv.Itoa(int(localPort))
+}
+
+// companionIntercepts returns the names of the intercepts that were created alongside the intercept
+// with the given name because --port was repeated.
+func companionIntercepts(name string, iis []*manager.InterceptInfo) []string {
+	var names []string
+	for _, ii := range iis {
+		if ii.Spec.Parent == name {
+			names = append(names, ii.Spec.Name)
+		}
+	}
+	return names
+}
+
+// createCompanionIntercepts creates one intercept for each additional --port. The companions share
+// workload, service, and mechanism with the intercept described by ir, but have no mounts, previews,
+// or additional to-pod ports.
+func (is *interceptState) createCompanionIntercepts(ctx context.Context, ir *connector.CreateInterceptRequest) ([]*manager.InterceptInfo, error) {
+	iis := make([]*manager.InterceptInfo, 0, len(is.args.extraPorts))
+	for _, portSpec := range is.args.extraPorts {
+		local, _, svcPortID, err := parsePort(portSpec, false)
+		if err != nil {
+			return iis, err
+		}
+		spec := proto.Clone(ir.Spec).(*manager.InterceptSpec)
+		spec.Name = companionInterceptName(is.args.name, local)
+		spec.Parent = is.args.name
+		spec.TargetPort = int32(local)
+		spec.ServicePortIdentifier = svcPortID
+		spec.LocalPorts = nil
+		spec.ExtraPorts = nil
+		r, err := is.connectorClient.CreateIntercept(ctx, &connector.CreateInterceptRequest{
+			Spec:                spec,
+			AgentImage:          ir.AgentImage,
+			InterceptTimeout:    ir.InterceptTimeout,
+			AgentInstallTimeout: ir.AgentInstallTimeout,
+		})
+		if err != nil {
+			return iis, fmt.Errorf("connector.CreateIntercept: %w", err)
+		}
+		if r.Error != common.InterceptError_UNSPECIFIED {
+			return iis, InterceptError(r)
+		}
+		iis = append(iis, r.InterceptInfo)
+	}
+	return iis, nil
+}
+
 func (is *interceptState) getMountPoint() (string, bool, error) {
 	mountPoint := ""
 	doMount, err := strconv.ParseBool(is.args.mount)
@@ -548,7 +729,7 @@ func (is *interceptState) getMountPoint() (string, bool, error) {
 		doMount = len(mountPoint) > 0
 		err = nil
 	}
-	if doMount {
+	if doMount && !is.args.dryRun {
 		mountPoint, err = prepareMount(mountPoint)
 	}
 	return mountPoint, doMount, err
@@ -711,7 +892,7 @@ func (is *interceptState) EnsureState(ctx context.Context) (acquired bool, err e
 		// local-only
 		return true, nil
 	}
-	fmt.Fprintf(is.cmd.OutOrStdout(), "Using %s %s\n", r.WorkloadKind, args.agentName)
+	fmt.Fprintf(is.cmd.OutOrStdout(), "Using %s %s\n", strings.ToLower(r.WorkloadKind), args.agentName)
 	var intercept *manager.InterceptInfo
 
 	// Add metadata to scout from InterceptResult
@@ -772,12 +953,17 @@ func (is *interceptState) EnsureState(ctx context.Context) (acquired bool, err e
 		}
 	}
 
+	companions, err := is.createCompanionIntercepts(ctx, ir)
+	if err != nil {
+		return true, err
+	}
+
 	var volumeMountProblem error
 	doMount, err := strconv.ParseBool(args.mount)
 	if doMount || err != nil {
 		volumeMountProblem = checkMountCapability(ctx)
 	}
-	fmt.Fprintln(is.cmd.OutOrStdout(), DescribeIntercepts([]*manager.InterceptInfo{intercept}, volumeMountProblem, false))
+	fmt.Fprintln(is.cmd.OutOrStdout(), DescribeIntercepts(append([]*manager.InterceptInfo{intercept}, companions...), volumeMountProblem, false))
 	return true, nil
 }
 
@@ -787,14 +973,21 @@ func (is *interceptState) DeactivateState(ctx context.Context) error {
 
 func removeIntercept(ctx context.Context, name string) error {
 	return cliutil.WithStartedConnector(ctx, true, func(ctx context.Context, connectorClient connector.ConnectorClient) error {
-		var r *connector.InterceptResult
-		var err error
-		r, err = connectorClient.RemoveIntercept(dcontext.WithoutCancel(ctx), &manager.RemoveInterceptRequest2{Name: name})
-		if err != nil {
-			return err
+		ctx = dcontext.WithoutCancel(ctx)
+
+		// Intercepts created by a repeated --port are removed together with the named intercept.
+		var companions []string
+		if ci, err := connectorClient.Status(ctx, &empty.Empty{}); err == nil {
+			companions = companionIntercepts(name, ci.GetIntercepts().GetIntercepts())
 		}
-		if r.Error != common.InterceptError_UNSPECIFIED {
-			return InterceptError(r)
+		for _, n := range append([]string{name}, companions...) {
+			r, err := connectorClient.RemoveIntercept(ctx, &manager.RemoveInterceptRequest2{Name: n})
+			if err != nil {
+				return err
+			}
+			if r.Error != common.InterceptError_UNSPECIFIED {
+				return InterceptError(r)
+			}
 		}
 		return nil
 	})
@@ -810,11 +1003,30 @@ func validateDockerArgs(args []string) error {
 }
 
 func (is *interceptState) startInDocker(ctx context.Context, envFile string, args []string) (*dexec.Cmd, error) {
-	ourArgs := []string{
-		"run",
-		"--dns-search", "tel2-search",
-		"--env-file", envFile,
+	dockerMount := ""
+	if is.mountPoint != "" { // do we have a mount point at all?
+		if dockerMount = is.args.dockerMount; dockerMount == "" {
+			dockerMount = is.mountPoint
+		}
 	}
+	if dockerHostNetwork(args) && is.dockerPort != 0 && is.dockerPort != is.localPort {
+		dlog.Warnf(ctx, "the container uses the host network, so it must listen on port %d rather than on port %d", is.localPort, is.dockerPort)
+	}
+	ourArgs := dockerRunArgs(fmt.Sprintf("intercept-%s-%d", is.args.name, is.localPort), envFile, is.localPort, is.dockerPort, is.mountPoint, dockerMount, args)
+	return proc.Start(ctx, nil, "docker", ourArgs...)
+}
+
+// dockerRunArgs returns the arguments to pass to "docker run" when starting an intercept handler
+// container. The given args are the user's arguments, and they are appended last.
+func dockerRunArgs(name, envFile string, localPort, dockerPort uint16, mountPoint, dockerMount string, args []string) []string {
+	ourArgs := []string{"run"}
+	hostNetwork := dockerHostNetwork(args)
+	if !hostNetwork {
+		// Docker refuses DNS options in combination with the host network. They aren't needed there anyway,
+		// since the container then uses the host's resolver.
+		ourArgs = append(ourArgs, "--dns-search", "tel2-search")
+	}
+	ourArgs = append(ourArgs, "--env-file", envFile)
 	hasArg := func(s string) bool {
 		for _, arg := range args {
 			if s == arg {
@@ -824,23 +1036,33 @@ func (is *interceptState) startInDocker(ctx context.Context, envFile string, arg
 		return false
 	}
 	if !hasArg("--name") {
-		ourArgs = append(ourArgs, "--name", fmt.Sprintf("intercept-%s-%d", is.args.name, is.localPort))
+		ourArgs = append(ourArgs, "--name", name)
 	}
 
-	if is.dockerPort != 0 {
-		ourArgs = append(ourArgs, "-p", fmt.Sprintf("%d:%d", is.localPort, is.dockerPort))
+	// Published ports are discarded when using the host network.
+	if dockerPort != 0 && !hostNetwork {
+		ourArgs = append(ourArgs, "-p", fmt.Sprintf("%d:%d", localPort, dockerPort))
 	}
 
-	dockerMount := ""
-	if is.mountPoint != "" { // do we have a mount point at all?
-		if dockerMount = is.args.dockerMount; dockerMount == "" {
-			dockerMount = is.mountPoint
-		}
-	}
 	if dockerMount != "" {
-		ourArgs = append(ourArgs, "-v", fmt.Sprintf("%s:%s", is.mountPoint, dockerMount))
+		ourArgs = append(ourArgs, "-v", fmt.Sprintf("%s:%s", mountPoint, dockerMount))
+	}
+	return append(ourArgs, args...)
+}
+
+// dockerHostNetwork returns true if the given "docker run" arguments makes the container use the host network.
+func dockerHostNetwork(args []string) bool {
+	for i, arg := range args {
+		switch arg {
+		case "--network", "--net":
+			if i+1 < len(args) && args[i+1] == "host" {
+				return true
+			}
+		case "--network=host", "--net=host":
+			return true
+		}
 	}
-	return proc.Start(ctx, nil, "docker", append(ourArgs, args...)...)
+	return false
 }
 
 func (is *interceptState) writeEnvFile() error {
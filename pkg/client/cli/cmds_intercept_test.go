@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+func Test_validateExtraPorts(t *testing.T) {
+	tests := []struct {
+		name       string
+		port       string
+		extraPorts []string
+		wantErr    string
+	}{
+		{
+			name: "single port",
+			port: "8080",
+		},
+		{
+			name:       "named service ports",
+			port:       "8080:http",
+			extraPorts: []string{"9090:grpc"},
+		},
+		{
+			name:       "numbered service ports",
+			port:       "8080:80",
+			extraPorts: []string{"9090:9000", "9091:9001"},
+		},
+		{
+			name:       "primary without identifier",
+			port:       "8080",
+			extraPorts: []string{"9090:grpc"},
+			wantErr:    "each port must be of the format",
+		},
+		{
+			name:       "extra without identifier",
+			port:       "8080:http",
+			extraPorts: []string{"9090"},
+			wantErr:    "each port must be of the format",
+		},
+		{
+			name:       "duplicate local port",
+			port:       "8080:http",
+			extraPorts: []string{"8080:grpc"},
+			wantErr:    "local port 8080 is used more than once",
+		},
+		{
+			name:       "duplicate service port",
+			port:       "8080:http",
+			extraPorts: []string{"9090:http"},
+			wantErr:    "service port http is intercepted more than once",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local, _, svcPortID, err := parsePort(tt.port, false)
+			assert.NoError(t, err)
+			is := &interceptState{args: interceptArgs{port: tt.port, extraPorts: tt.extraPorts}, localPort: local}
+			err = is.validateExtraPorts(svcPortID)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_companionIntercepts(t *testing.T) {
+	ii := func(name, parent string) *manager.InterceptInfo {
+		return &manager.InterceptInfo{Spec: &manager.InterceptSpec{
+			Name:      name,
+			Agent:     "echo",
+			Namespace: "ns",
+			Parent:    parent,
+		}}
+	}
+	iis := []*manager.InterceptInfo{
+		ii("echo-ns", ""),
+		ii("echo-ns-9090", "echo-ns"),
+		ii("echo-ns-9091", "echo-ns"),
+		ii("echo-ns-9092", ""),       // created by itself, although the name matches
+		ii("other-9093", "other"),    // companion of another intercept
+		ii("echo-ns-9094", "echo-n"), // companion of another intercept with a similar name
+	}
+	assert.Equal(t, []string{"echo-ns-9090", "echo-ns-9091"}, companionIntercepts("echo-ns", iis))
+	assert.Empty(t, companionIntercepts("echo-ns-9090", iis))
+	assert.Empty(t, companionIntercepts("missing", iis))
+}
+
+func Test_dockerRunArgs(t *testing.T) {
+	tests := []struct {
+		name        string
+		dockerPort  uint16
+		dockerMount string
+		args        []string
+		want        []string
+	}{
+		{
+			name:       "bridge network",
+			dockerPort: 8080,
+			args:       []string{"--rm", "image:tag"},
+			want: []string{
+				"run", "--dns-search", "tel2-search", "--env-file", "x.env", "--name", "intercept-echo-9000",
+				"-p", "9000:8080", "--rm", "image:tag",
+			},
+		},
+		{
+			name:        "user provided name and mount",
+			dockerPort:  9000,
+			dockerMount: "/var/run",
+			args:        []string{"--name", "mine", "image:tag"},
+			want: []string{
+				"run", "--dns-search", "tel2-search", "--env-file", "x.env",
+				"-p", "9000:9000", "-v", "/tmp/mnt:/var/run", "--name", "mine", "image:tag",
+			},
+		},
+		{
+			name:       "host network",
+			dockerPort: 9000,
+			args:       []string{"--network", "host", "image:tag"},
+			want:       []string{"run", "--env-file", "x.env", "--name", "intercept-echo-9000", "--network", "host", "image:tag"},
+		},
+		{
+			name:       "host network short form",
+			dockerPort: 9000,
+			args:       []string{"--net=host", "image:tag"},
+			want:       []string{"run", "--env-file", "x.env", "--name", "intercept-echo-9000", "--net=host", "image:tag"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := dockerRunArgs("intercept-echo-9000", "x.env", 9000, tt.dockerPort, "/tmp/mnt", tt.dockerMount, tt.args)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_interceptPlanPrint(t *testing.T) {
+	plan := &interceptPlan{
+		Name:           "echo",
+		Namespace:      "some-ns",
+		WorkloadKind:   "statefulset",
+		Workload:       "echo",
+		AgentInjection: true,
+		Service:        "echo",
+		Ports: []interceptPlanPort{
+			{Intercept: "echo", TargetHost: "127.0.0.1", TargetPort: 8080, ServicePortName: "http", ServicePort: 80},
+			{Intercept: "echo-9090", TargetHost: "127.0.0.1", TargetPort: 9090, ServicePort: 9090},
+		},
+		Mechanism:     "http",
+		MechanismArgs: []string{"--http-header=x-user=me"},
+	}
+	out := &strings.Builder{}
+	plan.print(out)
+	assert.Equal(t, `Dry-run of intercept "echo", nothing was changed in the cluster
+    Workload       : statefulset echo.some-ns
+    Traffic agent  : will be injected, which restarts the workload's pods
+    Service        : echo
+    Ports          : service port http (80) -> 127.0.0.1:8080
+                     service port 9090 -> 127.0.0.1:9090
+    Mechanism      : http
+    Mechanism args : --http-header=x-user=me
+`, out.String())
+}
@@ -4,16 +4,20 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/pkg/browser"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/telepresenceio/telepresence/rpc/v2/connector"
 	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/cliutil"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
 	"github.com/telepresenceio/telepresence/v2/pkg/proc"
 )
@@ -55,6 +59,12 @@ func ClusterIdCommand() *cobra.Command {
 func connectCommand() *cobra.Command {
 	var dnsIP string
 	var mappedNamespaces []string
+	var dnsSearch, dnsResolvers []string
+	var validateContextNames []string
+	var switchContext, switchNamespace string
+	var sessionDuration, connectTimeout time.Duration
+	var demo, noReconnect bool
+	var metricsListen string
 
 	kubeFlags := pflag.NewFlagSet("Kubernetes flags", 0)
 	cmd := &cobra.Command{
@@ -62,9 +72,46 @@ func connectCommand() *cobra.Command {
 		Args:  cobra.ArbitraryArgs,
 		Short: "Connect to a cluster",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(validateContextNames) > 0 {
+				if err := validateContexts(cmd.Context(), kubeFlags, validateContextNames, cmd.OutOrStdout()); err != nil {
+					return err
+				}
+			}
 			request := &connector.ConnectRequest{
 				KubeFlags:        kubeFlagMap(kubeFlags),
 				MappedNamespaces: mappedNamespaces,
+				Demo:             demo,
+				MetricsListen:    metricsListen,
+				NoReconnect:      noReconnect,
+				DnsSearch:        dnsSearch,
+			}
+			var err error
+			if request.DnsResolvers, err = parseDNSResolvers(dnsResolvers); err != nil {
+				return err
+			}
+			if demo && (switchContext != "" || switchNamespace != "") {
+				return errcat.User.New("--demo cannot be combined with --switch-context or --switch-namespace")
+			}
+			if sessionDuration < 0 {
+				return errcat.User.New("--session-duration cannot be negative")
+			}
+			if sessionDuration > 0 {
+				request.SessionDuration = durationpb.New(sessionDuration)
+			}
+			if connectTimeout > 0 {
+				request.ConnectTimeout = durationpb.New(connectTimeout)
+			}
+			if switchContext != "" || switchNamespace != "" {
+				if switchContext != "" {
+					if kubeFlags.Changed("context") {
+						return errcat.User.New("--switch-context and --context are mutually exclusive")
+					}
+					request.KubeFlags["context"] = switchContext
+				}
+				if switchNamespace != "" {
+					request.KubeFlags["namespace"] = switchNamespace
+				}
+				request.SwitchContext = true
 			}
 
 			if len(args) == 0 {
@@ -94,15 +141,82 @@ func connectCommand() *cobra.Command {
 		"mapped-namespaces", nil, ``+
 			`Comma separated list of namespaces considered by DNS resolver and NAT for outbound connections. `+
 			`Defaults to all namespaces`)
+	nwFlags.StringArrayVar(&dnsSearch,
+		"dns-search", nil, ``+
+			`Domain that names are resolved in by the cluster DNS, and that is added to the DNS search path. `+
+			`May be repeated`)
+	nwFlags.StringArrayVar(&dnsResolvers,
+		"dns-resolver", nil, ``+
+			`Resolver for names in a domain, in the form <domain>=<ip>[:<port>], e.g. "corp.example.com=10.0.0.10". `+
+			`Names in the domain are sent to that resolver instead of the cluster DNS, which is used as a `+
+			`fallback when the resolver fails. May be repeated`)
 	flags.AddFlagSet(nwFlags)
 
+	flags.StringSliceVar(&validateContextNames,
+		"validate-contexts", nil, ``+
+			`Comma separated list of kubeconfig contexts to validate before connecting. The connect fails without `+
+			`connecting if any of them doesn't exist or if its cluster is unreachable with the context's credentials`)
+
+	flags.StringVar(&switchContext,
+		"switch-context", "", ``+
+			`Reconnect an existing session to the given kubeconfig context without restarting the daemons. `+
+			`The context is validated before the current session is replaced. Intercepts of the current session `+
+			`are removed and reported`)
+	flags.StringVar(&switchNamespace,
+		"switch-namespace", "", ``+
+			`Reconnect an existing session using the given namespace without restarting the daemons. `+
+			`Intercepts of the current session are removed and reported`)
+	flags.DurationVar(&sessionDuration,
+		"session-duration", 0, ``+
+			`End the session when the given duration has elapsed, e.g. "2h". Short-lived cluster tokens obtained `+
+			`from a credential plugin are refreshed before they expire during the session. Zero means no limit`)
+
 	kubeConfig := genericclioptions.NewConfigFlags(false)
 	kubeConfig.Namespace = nil // "connect", don't take --namespace
 	kubeConfig.AddFlags(kubeFlags)
 	flags.AddFlagSet(kubeFlags)
+	flags.BoolVar(&demo,
+		"demo", false, ``+
+			`Connect to an in-process fake cluster with a couple of sample workloads instead of a real cluster. `+
+			`Workloads can be listed and intercepted, but no traffic reaches the cluster`)
+	flags.DurationVar(&connectTimeout,
+		"connect-timeout", 0, ``+
+			`Maximum time for each phase of the connect: connecting to the cluster, installing the traffic-manager, `+
+			`and connecting to the traffic-manager. Defaults to the timeouts.clusterConnect, timeouts.helm, and `+
+			`timeouts.trafficManagerConnect settings of the config.yml`)
+
+	flags.StringVar(&metricsListen,
+		"metrics-listen", "", ``+
+			`Serve Prometheus metrics of the user daemon on the given address while connected, e.g. "127.0.0.1:9900". `+
+			`An address without a host, e.g. ":9900", is bound to localhost. Metrics are not served by default`)
+	flags.BoolVar(&noReconnect,
+		"no-reconnect", false, ``+
+			`Don't reconnect automatically when the connection to the cluster is lost. By default, the session is `+
+			`replaced using backoff between attempts, and its intercepts are recreated`)
+
+	_ = cmd.RegisterFlagCompletionFunc("mapped-namespaces", completeNamespaceList)
+	_ = cmd.RegisterFlagCompletionFunc("switch-namespace", completeNamespaces)
 	return cmd
 }
 
+// parseDNSResolvers parses --dns-resolver flag values in the form <domain>=<ip>[:<port>] into a map.
+func parseDNSResolvers(specs []string) (map[string]string, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	resolvers := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		domain, addr, ok := strings.Cut(spec, "=")
+		domain = strings.Trim(strings.TrimSpace(domain), ".")
+		addr = strings.TrimSpace(addr)
+		if !ok || domain == "" || addr == "" {
+			return nil, errcat.User.Newf("invalid --dns-resolver %q, must be in the form <domain>=<ip>[:<port>]", spec)
+		}
+		resolvers[domain] = addr
+	}
+	return resolvers, nil
+}
+
 func dashboardCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:  "dashboard",
@@ -134,17 +248,23 @@ func dashboardCommand() *cobra.Command {
 func quitCommand() *cobra.Command {
 	quitRootDaemon := false
 	quitUserDaemon := false
+	killDaemons := false
 	cmd := &cobra.Command{
 		Use:  "quit",
 		Args: cobra.NoArgs,
 
 		Short: "Tell telepresence daemon to quit",
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if killDaemons {
+				return cliutil.KillDaemons(cmd.Context())
+			}
 			return cliutil.Disconnect(cmd.Context(), quitUserDaemon, quitRootDaemon)
 		},
 	}
 	flags := cmd.Flags()
 	flags.BoolVarP(&quitRootDaemon, "root-daemon", "r", false, "stop root daemon")
 	flags.BoolVarP(&quitUserDaemon, "user-daemon", "u", false, "stop user daemon")
+	flags.BoolVar(&killDaemons, "kill-daemons", false,
+		"stop both daemons, terminate any daemon process that doesn't quit (e.g. an orphaned root daemon), and remove stale sockets")
 	return cmd
 }
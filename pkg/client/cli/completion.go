@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/cliutil"
+)
+
+// completionTimeout is the maximum time that a completion function will wait for the cluster to respond.
+const completionTimeout = 5 * time.Second
+
+// completeInterceptableWorkloads completes the first argument of a command with the names of the workloads that
+// can be intercepted in the namespace given by the command's --namespace flag.
+func completeInterceptableWorkloads(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeWorkloadFlag(cmd, args, toComplete)
+}
+
+// completeWorkloadFlag completes a flag value with the names of the workloads that can be intercepted in the
+// namespace given by the command's --namespace flag.
+func completeWorkloadFlag(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	namespace, _ := cmd.Flags().GetString("namespace")
+	var names []string
+	_ = withCompletionConnector(cmd, func(ctx context.Context, connectorClient connector.ConnectorClient) error {
+		wis, err := connectorClient.List(ctx, &connector.ListRequest{
+			Filter:    connector.ListRequest_INTERCEPTABLE,
+			Namespace: namespace,
+		})
+		if err != nil {
+			return err
+		}
+		for _, wi := range wis.Workloads {
+			names = append(names, wi.Name)
+		}
+		return nil
+	})
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeInterceptNames completes the first argument of a command with the names of the intercepts of the
+// current session.
+func completeInterceptNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	_ = withCompletionConnector(cmd, func(ctx context.Context, connectorClient connector.ConnectorClient) error {
+		wis, err := connectorClient.List(ctx, &connector.ListRequest{Filter: connector.ListRequest_INTERCEPTS})
+		if err != nil {
+			return err
+		}
+		for _, wi := range wis.Workloads {
+			for _, ii := range wi.InterceptInfos {
+				names = append(names, ii.Spec.Name)
+			}
+		}
+		return nil
+	})
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// withCompletionConnector calls the given function with a connector client, provided that the user daemon is
+// running. Completions are never allowed to start the daemons, so when the daemon isn't running, the completion
+// just degrades to an empty list.
+func withCompletionConnector(cmd *cobra.Command, f func(context.Context, connector.ConnectorClient) error) error {
+	ctx, cancel := context.WithTimeout(cmd.Context(), completionTimeout)
+	defer cancel()
+	return cliutil.WithStartedConnector(ctx, false, f)
+}
+
+// completeNamespaces completes a flag value with the names of the namespaces of the cluster. The cluster is
+// determined by the kubeconfig, taking the command's kubernetes flags, such as --context, into account. An
+// empty list is returned if the cluster cannot be reached.
+func completeNamespaces(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	ctx, cancel := context.WithTimeout(cmd.Context(), completionTimeout)
+	defer cancel()
+	names, _ := listNamespaces(ctx, completionConfigFlags(cmd.Flags()))
+	return filterCompletions(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeNamespaceList is like completeNamespaces but for flags that contain a comma separated list of
+// namespaces. Only the last element of the list is completed.
+func completeNamespaceList(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if i := strings.LastIndexByte(toComplete, ','); i >= 0 {
+		prefix = toComplete[:i+1]
+		toComplete = toComplete[i+1:]
+	}
+	names, directive := completeNamespaces(cmd, args, toComplete)
+	for i, name := range names {
+		names[i] = prefix + name
+	}
+	return names, directive
+}
+
+// completionConfigFlags returns ConfigFlags that reflect the kubernetes flags, such as --context or --kubeconfig,
+// that have been set in the given flag set.
+func completionConfigFlags(flags *pflag.FlagSet) *genericclioptions.ConfigFlags {
+	configFlags := genericclioptions.NewConfigFlags(false)
+	kubeFlags := pflag.NewFlagSet("", 0)
+	configFlags.AddFlags(kubeFlags)
+	kubeFlags.VisitAll(func(kf *pflag.Flag) {
+		if f := flags.Lookup(kf.Name); f != nil && f.Changed {
+			_ = kf.Value.Set(f.Value.String())
+		}
+	})
+	return configFlags
+}
+
+func listNamespaces(ctx context.Context, configFlags *genericclioptions.ConfigFlags) ([]string, error) {
+	restConfig, err := configFlags.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	ki, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	nsl, err := ki.CoreV1().Namespaces().List(ctx, meta.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(nsl.Items))
+	for i := range nsl.Items {
+		names[i] = nsl.Items[i].Name
+	}
+	return names, nil
+}
+
+// filterCompletions returns the sorted and unique names that start with the given prefix.
+func filterCompletions(names []string, prefix string) []string {
+	sort.Strings(names)
+	filtered := make([]string, 0, len(names))
+	for i, name := range names {
+		if strings.HasPrefix(name, prefix) && (i == 0 || names[i-1] != name) {
+			filtered = append(filtered, name)
+		}
+	}
+	return filtered
+}
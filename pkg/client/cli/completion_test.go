@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+const completionKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: reachable
+  cluster:
+    server: %[1]s
+- name: unreachable
+  cluster:
+    server: %[2]s
+contexts:
+- name: ctx-ok
+  context:
+    cluster: reachable
+    user: user
+- name: ctx-down
+  context:
+    cluster: unreachable
+    user: user
+users:
+- name: user
+  user:
+    token: token
+current-context: ctx-ok
+`
+
+func Test_filterCompletions(t *testing.T) {
+	assert.Equal(t, []string{"echo", "echo-easy"}, filterCompletions([]string{"hello", "echo-easy", "echo", "echo"}, "ec"))
+	assert.Equal(t, []string{"echo", "hello"}, filterCompletions([]string{"hello", "echo"}, ""))
+	assert.Empty(t, filterCompletions(nil, "x"))
+}
+
+func Test_completeNamespaces(t *testing.T) {
+	reachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"NamespaceList","apiVersion":"v1","items":[` +
+			`{"metadata":{"name":"default"}},{"metadata":{"name":"dev"}},{"metadata":{"name":"kube-system"}}]}`))
+	}))
+	defer reachable.Close()
+
+	// Start and immediately close a server to get a URL that refuses connections
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(fmt.Sprintf(completionKubeconfig, reachable.URL, down.URL)), 0o600))
+
+	newCmd := func(kubeContext string) *cobra.Command {
+		cmd := connectCommand()
+		cmd.SetContext(newTestContext(t))
+		require.NoError(t, cmd.Flags().Set("kubeconfig", kubeconfig))
+		if kubeContext != "" {
+			require.NoError(t, cmd.Flags().Set("context", kubeContext))
+		}
+		return cmd
+	}
+
+	t.Run("single namespace", func(t *testing.T) {
+		names, directive := completeNamespaces(newCmd(""), nil, "d")
+		assert.Equal(t, []string{"default", "dev"}, names)
+		assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+	})
+
+	t.Run("namespace list", func(t *testing.T) {
+		names, _ := completeNamespaceList(newCmd(""), nil, "dev,k")
+		assert.Equal(t, []string{"dev,kube-system"}, names)
+	})
+
+	t.Run("unreachable cluster", func(t *testing.T) {
+		names, directive := completeNamespaces(newCmd("ctx-down"), nil, "")
+		assert.Empty(t, names)
+		assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+	})
+}
+
+func Test_completeWorkloadsWithoutDaemon(t *testing.T) {
+	if _, err := os.Stat(client.ConnectorSocketName); err == nil {
+		t.Skip("a user daemon is running")
+	}
+	cmd := interceptCommand(newTestContext(t))
+	cmd.SetContext(newTestContext(t))
+	names, directive := completeInterceptableWorkloads(cmd, nil, "")
+	assert.Empty(t, names)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	cmd = leaveCommand()
+	cmd.SetContext(newTestContext(t))
+	names, directive = completeInterceptNames(cmd, nil, "")
+	assert.Empty(t, names)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
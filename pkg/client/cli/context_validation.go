@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+)
+
+// contextValidation is the result of validating one kubeconfig context.
+type contextValidation struct {
+	Context string
+	Server  string
+	Version string
+	Error   string
+}
+
+// validateContexts checks that each of the given contexts exists in the kubeconfig described by the
+// given kubeFlags, and that the API server of its cluster is reachable using the credentials of that
+// context. The contexts are validated concurrently. A table with one row per context is written to out,
+// and an error naming the failing contexts is returned unless all of them were valid.
+func validateContexts(ctx context.Context, kubeFlags *pflag.FlagSet, contexts []string, out io.Writer) error {
+	results := make([]contextValidation, len(contexts))
+	wg := sync.WaitGroup{}
+	wg.Add(len(contexts))
+	for i, ctxName := range contexts {
+		go func(i int, ctxName string) {
+			defer wg.Done()
+			results[i] = validateContext(ctx, kubeFlags, ctxName)
+		}(i, ctxName)
+	}
+	wg.Wait()
+
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONTEXT\tSERVER\tRESULT")
+	var failed []string
+	for _, r := range results {
+		result := "ok, server version " + r.Version
+		if r.Error != "" {
+			result = "error: " + r.Error
+			failed = append(failed, r.Context)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Context, r.Server, result)
+	}
+	_ = tw.Flush()
+
+	if len(failed) > 0 {
+		return errcat.User.Newf("validation failed for %d of %d contexts: %s", len(failed), len(results), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func validateContext(ctx context.Context, kubeFlags *pflag.FlagSet, ctxName string) (result contextValidation) {
+	result.Context = ctxName
+	configFlags := genericclioptions.NewConfigFlags(false)
+	flags := pflag.NewFlagSet("", 0)
+	configFlags.AddFlags(flags)
+	for k, v := range kubeFlagMap(kubeFlags) {
+		if err := flags.Set(k, v); err != nil {
+			result.Error = fmt.Sprintf("error processing kubectl flag --%s=%s: %v", k, v, err)
+			return result
+		}
+	}
+	*configFlags.Context = ctxName
+
+	configLoader := configFlags.ToRawKubeConfigLoader()
+	config, err := configLoader.RawConfig()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	kc, ok := config.Contexts[ctxName]
+	if !ok {
+		result.Error = fmt.Sprintf("context %q does not exist in the kubeconfig", ctxName)
+		return result
+	}
+	if cluster, ok := config.Clusters[kc.Cluster]; ok {
+		result.Server = cluster.Server
+	}
+
+	restConfig, err := configLoader.ClientConfig()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	timeout := 20 * time.Second
+	if cfg := client.GetConfig(ctx); cfg != nil {
+		timeout = cfg.Timeouts.Get(client.TimeoutClusterConnect)
+	}
+	restConfig.Timeout = timeout
+	dc, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	info, err := dc.ServerVersion()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Version = info.GitVersion
+	return result
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+)
+
+const validationKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: reachable
+  cluster:
+    server: %[1]s
+    insecure-skip-tls-verify: true
+- name: unreachable
+  cluster:
+    server: %[2]s
+contexts:
+- name: ctx-ok
+  context:
+    cluster: reachable
+    user: good
+- name: ctx-bad-auth
+  context:
+    cluster: reachable
+    user: bad
+- name: ctx-down
+  context:
+    cluster: unreachable
+    user: good
+users:
+- name: good
+  user:
+    token: good-token
+- name: bad
+  user:
+    token: bad-token
+current-context: ctx-ok
+`
+
+func Test_validateContexts(t *testing.T) {
+	reachable := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"major":"1","minor":"24","gitVersion":"v1.24.1"}`))
+	}))
+	defer reachable.Close()
+
+	// Start and immediately close a server to get a URL that refuses connections
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(kubeconfig, []byte(fmt.Sprintf(validationKubeconfig, reachable.URL, down.URL)), 0600))
+
+	kubeFlags := pflag.NewFlagSet("Kubernetes flags", 0)
+	genericclioptions.NewConfigFlags(false).AddFlags(kubeFlags)
+	require.NoError(t, kubeFlags.Set("kubeconfig", kubeconfig))
+
+	// rows returns the table written by validateContexts as a map from context name to the row of that context.
+	rows := func(out string) map[string]string {
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Regexp(t, `^CONTEXT\s+SERVER\s+RESULT$`, lines[0])
+		m := make(map[string]string, len(lines)-1)
+		for _, line := range lines[1:] {
+			m[strings.Fields(line)[0]] = line
+		}
+		return m
+	}
+
+	t.Run("all reachable", func(t *testing.T) {
+		out := &strings.Builder{}
+		require.NoError(t, validateContexts(context.Background(), kubeFlags, []string{"ctx-ok"}, out))
+		r := rows(out.String())
+		assert.Len(t, r, 1)
+		assert.Regexp(t, `^ctx-ok\s+`+regexp.QuoteMeta(reachable.URL)+`\s+ok, server version v1\.24\.1$`, r["ctx-ok"])
+	})
+
+	t.Run("mix of reachable and unreachable", func(t *testing.T) {
+		out := &strings.Builder{}
+		err := validateContexts(context.Background(), kubeFlags, []string{"ctx-ok", "ctx-bad-auth", "ctx-down", "ctx-missing"}, out)
+		require.Error(t, err)
+		assert.Equal(t, errcat.User, errcat.GetCategory(err))
+		assert.Contains(t, err.Error(), "validation failed for 3 of 4 contexts: ctx-bad-auth, ctx-down, ctx-missing")
+
+		r := rows(out.String())
+		assert.Len(t, r, 4)
+		assert.Contains(t, r["ctx-ok"], "ok, server version v1.24.1")
+		assert.Contains(t, r["ctx-bad-auth"], "error: ")
+		assert.Regexp(t, `^ctx-down\s+`+regexp.QuoteMeta(down.URL)+`\s+error: `, r["ctx-down"])
+		assert.Contains(t, r["ctx-missing"], `error: context "ctx-missing" does not exist in the kubeconfig`)
+	})
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/cliutil"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/throttle"
+)
+
+// batchCommand is the safeCobraCommand of one intercept in a batch. Its output is collected so that it
+// can be printed in one piece once the intercept completes, instead of being interleaved with the output
+// of the intercepts that run concurrently.
+type batchCommand struct {
+	safeCobraCommand
+	out io.Writer
+}
+
+func (c batchCommand) OutOrStdout() io.Writer {
+	return c.out
+}
+
+// validateBatchArgs checks that the flags of an intercept --batch make sense when applied to each one of
+// the given workloads.
+func validateBatchArgs(cmd *cobra.Command, args *interceptArgs, workloads []string) error {
+	if cmd.ArgsLenAtDash() >= 0 {
+		return errcat.User.New("--batch cannot be combined with a command")
+	}
+	switch {
+	case args.localOnly:
+		return errcat.User.New("--batch cannot be combined with --local-only")
+	case args.agentName != "":
+		return errcat.User.New("--batch cannot be combined with --workload, the arguments are the names of the workloads")
+	case args.dockerRun:
+		return errcat.User.New("--batch cannot be combined with --docker-run")
+	case args.dryRun:
+		return errcat.User.New("--batch cannot be combined with --dry-run")
+	case args.envFile != "" || args.envJSON != "":
+		return errcat.User.New("--batch cannot be combined with --env-file or --env-json")
+	}
+	if _, err := strconv.ParseBool(args.mount); err != nil {
+		return errcat.User.New(`--batch cannot be combined with a --mount path, use "true" or "false"`)
+	}
+	seen := make(map[string]struct{}, len(workloads))
+	for _, wl := range workloads {
+		if _, ok := seen[wl]; ok {
+			return errcat.User.Newf("workload %s is given more than once", wl)
+		}
+		seen[wl] = struct{}{}
+	}
+	return nil
+}
+
+// interceptBatch creates and retains one intercept for each of the given workloads. Creating an intercept
+// may inject a traffic-agent, which triggers a rolling restart of the workload, so the intercepts are
+// paced according to the --install-concurrency and --install-rate flags.
+func interceptBatch(cmd *cobra.Command, args interceptArgs, workloads []string) error {
+	return withConnector(cmd, true, nil, func(ctx context.Context, cs *connectorState) error {
+		return cliutil.WithManager(ctx, func(ctx context.Context, managerClient manager.ManagerClient) error {
+			return interceptWorkloads(ctx, args.throttle, workloads, cmd.OutOrStdout(), func(ctx context.Context, wl string, out io.Writer) error {
+				wa := args
+				wa.name, wa.agentName = wl, wl
+				if wa.namespace != "" {
+					wa.name += "-" + wa.namespace
+				}
+				// The preview spec is updated with the ingress of each intercept
+				wa.previewSpec = proto.Clone(args.previewSpec).(*manager.PreviewSpec)
+				is := newInterceptState(ctx, batchCommand{safeCobraCommand: safeCobraCommandImpl{cmd}, out: out}, wa, cs, managerClient)
+				defer is.scout.Close()
+				return client.WithEnsuredState(ctx, is, true, func() error { return nil })
+			})
+		})
+	})
+}
+
+// interceptWorkloads calls intercept once for each workload while respecting the limits of the given
+// throttle.Config. The output that each call writes is printed to out, together with the progress of the
+// batch, when the call completes.
+func interceptWorkloads(
+	ctx context.Context,
+	tc throttle.Config,
+	workloads []string,
+	out io.Writer,
+	intercept func(ctx context.Context, workload string, out io.Writer) error,
+) error {
+	outs := make(map[string]*bytes.Buffer, len(workloads))
+	for _, wl := range workloads {
+		outs[wl] = &bytes.Buffer{}
+	}
+	return throttle.Run(ctx, tc, workloads, func(ctx context.Context, wl string) error {
+		return intercept(ctx, wl, outs[wl])
+	}, func(done, total int, wl string, err error) {
+		if err != nil {
+			fmt.Fprintf(out, "[%d/%d] failed to intercept %s: %v\n", done, total, wl, err)
+		} else {
+			fmt.Fprintf(out, "[%d/%d] intercepted %s\n", done, total, wl)
+		}
+		_, _ = outs[wl].WriteTo(out)
+	})
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/throttle"
+)
+
+func workloadNames(n int) []string {
+	names := make([]string, n)
+	for i := range names {
+		names[i] = fmt.Sprintf("wl-%d", i)
+	}
+	return names
+}
+
+func Test_interceptWorkloads(t *testing.T) {
+	t.Run("concurrency", func(t *testing.T) {
+		const limit = 2
+		var current, maxSeen int32
+		err := interceptWorkloads(dlog.NewTestContext(t, false), throttle.Config{Concurrency: limit}, workloadNames(8), io.Discard,
+			func(_ context.Context, _ string, _ io.Writer) error {
+				c := atomic.AddInt32(&current, 1)
+				for {
+					m := atomic.LoadInt32(&maxSeen)
+					if c <= m || atomic.CompareAndSwapInt32(&maxSeen, m, c) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				atomic.AddInt32(&current, -1)
+				return nil
+			})
+		require.NoError(t, err)
+		assert.Equal(t, int32(limit), maxSeen)
+	})
+
+	t.Run("rate", func(t *testing.T) {
+		const rate = 20.0 // one start every 50ms
+		interval := time.Duration(float64(time.Second) / rate)
+		var mu sync.Mutex
+		var starts []time.Time
+		err := interceptWorkloads(dlog.NewTestContext(t, false), throttle.Config{Rate: rate}, workloadNames(5), io.Discard,
+			func(_ context.Context, _ string, _ io.Writer) error {
+				mu.Lock()
+				starts = append(starts, time.Now())
+				mu.Unlock()
+				return nil
+			})
+		require.NoError(t, err)
+		require.Len(t, starts, 5)
+		for i := 1; i < len(starts); i++ {
+			// allow for some timer granularity
+			assert.GreaterOrEqual(t, starts[i].Sub(starts[i-1]), interval-5*time.Millisecond)
+		}
+	})
+
+	t.Run("progress", func(t *testing.T) {
+		out := &strings.Builder{}
+		err := interceptWorkloads(dlog.NewTestContext(t, false), throttle.Config{Concurrency: 1}, workloadNames(3), out,
+			func(_ context.Context, wl string, out io.Writer) error {
+				if wl == "wl-1" {
+					return errors.New("boom")
+				}
+				fmt.Fprintf(out, "Using Deployment %s\n", wl)
+				return nil
+			})
+		require.Error(t, err)
+		assert.Equal(t, ""+
+			"[1/3] intercepted wl-0\n"+
+			"Using Deployment wl-0\n"+
+			"[2/3] failed to intercept wl-1: boom\n"+
+			"[3/3] intercepted wl-2\n"+
+			"Using Deployment wl-2\n",
+			out.String())
+	})
+}
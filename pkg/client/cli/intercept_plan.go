@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+)
+
+// interceptPlan describes what an intercept would do. It's the result of an intercept --dry-run.
+type interceptPlan struct {
+	Name           string              `json:"name"`
+	Namespace      string              `json:"namespace"`
+	LocalOnly      bool                `json:"local_only,omitempty"`
+	WorkloadKind   string              `json:"workload_kind,omitempty"`
+	Workload       string              `json:"workload,omitempty"`
+	AgentInjection bool                `json:"agent_injection,omitempty"`
+	Service        string              `json:"service,omitempty"`
+	Ports          []interceptPlanPort `json:"ports,omitempty"`
+	ToPod          []string            `json:"to_pod,omitempty"`
+	Mechanism      string              `json:"mechanism,omitempty"`
+	MechanismArgs  []string            `json:"mechanism_args,omitempty"`
+}
+
+// interceptPlanPort describes how traffic to a service port is routed to the local machine.
+type interceptPlanPort struct {
+	Intercept       string `json:"intercept"`
+	TargetHost      string `json:"target_host"`
+	TargetPort      int32  `json:"target_port"`
+	ServicePortName string `json:"service_port_name,omitempty"`
+	ServicePort     int32  `json:"service_port"`
+}
+
+// dryRun performs all validation and resolution of the intercept and returns its plan. Nothing is modified
+// in the cluster, and no mount point or local listener is created.
+func (is *interceptState) dryRun(ctx context.Context) (*interceptPlan, error) {
+	ir, err := is.createRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ir.DryRun = true
+	r, err := is.canDryRun(ctx, ir)
+	if err != nil {
+		return nil, err
+	}
+	spec := r.InterceptInfo.Spec
+	if spec.Agent == "" {
+		return &interceptPlan{Name: spec.Name, Namespace: spec.Namespace, LocalOnly: true}, nil
+	}
+	plan := &interceptPlan{
+		Name:           spec.Name,
+		Namespace:      spec.Namespace,
+		WorkloadKind:   strings.ToLower(r.WorkloadKind),
+		Workload:       spec.Agent,
+		AgentInjection: r.AgentInjection,
+		Service:        spec.ServiceName,
+		ToPod:          spec.LocalPorts,
+		Mechanism:      spec.Mechanism,
+		MechanismArgs:  spec.MechanismArgs,
+	}
+	plan.addPort(spec, r)
+
+	// Each additional --port results in a companion intercept, see createCompanionIntercepts
+	for _, portSpec := range is.args.extraPorts {
+		local, _, svcPortID, err := parsePort(portSpec, false)
+		if err != nil {
+			return nil, err
+		}
+		cs := proto.Clone(ir.Spec).(*manager.InterceptSpec)
+		cs.Name = companionInterceptName(is.args.name, local)
+		cs.TargetPort = int32(local)
+		cs.ServicePortIdentifier = svcPortID
+		cs.LocalPorts = nil
+		cs.ExtraPorts = nil
+		cr, err := is.canDryRun(ctx, &connector.CreateInterceptRequest{Spec: cs, DryRun: true})
+		if err != nil {
+			return nil, err
+		}
+		plan.addPort(cr.InterceptInfo.Spec, cr)
+	}
+	return plan, nil
+}
+
+func (is *interceptState) canDryRun(ctx context.Context, ir *connector.CreateInterceptRequest) (*connector.InterceptResult, error) {
+	r, err := is.connectorClient.CanIntercept(ctx, ir)
+	if err != nil {
+		return nil, fmt.Errorf("connector.CanIntercept: %w", err)
+	}
+	if r.Error != common.InterceptError_UNSPECIFIED {
+		return nil, InterceptError(r)
+	}
+	if r.InterceptInfo == nil {
+		// The connector ignored the dry_run field, so it's too old to know about it.
+		return nil, errcat.User.New("the user daemon does not support --dry-run, please quit and reconnect")
+	}
+	return r, nil
+}
+
+func (p *interceptPlan) addPort(spec *manager.InterceptSpec, r *connector.InterceptResult) {
+	p.Ports = append(p.Ports, interceptPlanPort{
+		Intercept:       spec.Name,
+		TargetHost:      spec.TargetHost,
+		TargetPort:      spec.TargetPort,
+		ServicePortName: r.ServiceProps.ServicePortIdentifier,
+		ServicePort:     r.ServiceProps.ServicePort,
+	})
+}
+
+// print writes the plan in human-readable form.
+func (p *interceptPlan) print(out io.Writer) {
+	fmt.Fprintf(out, "Dry-run of intercept %q, nothing was changed in the cluster\n", p.Name)
+	kvf := func(k, f string, args ...any) {
+		sep := ':'
+		if k == "" {
+			sep = ' '
+		}
+		fmt.Fprintf(out, "    %-15s%c %s\n", k, sep, fmt.Sprintf(f, args...))
+	}
+	if p.LocalOnly {
+		kvf("Local only", "outbound access to namespace %s", p.Namespace)
+		return
+	}
+	kvf("Workload", "%s %s.%s", p.WorkloadKind, p.Workload, p.Namespace)
+	if p.AgentInjection {
+		kvf("Traffic agent", "will be injected, which restarts the workload's pods")
+	} else {
+		kvf("Traffic agent", "already installed")
+	}
+	kvf("Service", "%s", p.Service)
+	for i, pp := range p.Ports {
+		k := ""
+		if i == 0 {
+			k = "Ports"
+		}
+		svcPort := strconv.Itoa(int(pp.ServicePort))
+		if pp.ServicePortName != "" {
+			svcPort = fmt.Sprintf("%s (%d)", pp.ServicePortName, pp.ServicePort)
+		}
+		kvf(k, "service port %s -> %s:%d", svcPort, pp.TargetHost, pp.TargetPort)
+	}
+	if len(p.ToPod) > 0 {
+		kvf("To pod", "%s", strings.Join(p.ToPod, ", "))
+	}
+	kvf("Mechanism", "%s", p.Mechanism)
+	if len(p.MechanismArgs) > 0 {
+		kvf("Mechanism args", "%s", strings.Join(p.MechanismArgs, " "))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -21,7 +22,14 @@ func kubeFlagMap(kubeFlags *pflag.FlagSet) map[string]string {
 	kubeFlagMap := make(map[string]string, kubeFlags.NFlag())
 	kubeFlags.VisitAll(func(flag *pflag.Flag) {
 		if flag.Changed {
-			kubeFlagMap[flag.Name] = flag.Value.String()
+			value := flag.Value.String()
+			if flag.Name == "kubeconfig" && value != "" {
+				// The connector daemon doesn't necessarily share the working directory of this process.
+				if absValue, err := filepath.Abs(value); err == nil {
+					value = absValue
+				}
+			}
+			kubeFlagMap[flag.Name] = value
 		}
 	})
 	return kubeFlagMap
@@ -42,9 +50,19 @@ type connectorState struct {
 //
 //  - Makes the connector.Connect gRPC call to set up networking
 func withConnector(cmd *cobra.Command, retain bool, request *connector.ConnectRequest, f func(context.Context, *connectorState) error) error {
+	return connectAndRun(cmd, retain, request, &connector.ConnectRequest{}, f)
+}
+
+// withConnectorNoInstall is like withConnector with a nil request, except that an implicit connect fails
+// instead of installing the traffic-manager when it isn't installed.
+func withConnectorNoInstall(cmd *cobra.Command, retain bool, f func(context.Context, *connectorState) error) error {
+	return connectAndRun(cmd, retain, nil, &connector.ConnectRequest{NoInstall: true}, f)
+}
+
+func connectAndRun(cmd *cobra.Command, retain bool, request, implicitRequest *connector.ConnectRequest, f func(context.Context, *connectorState) error) error {
 	return cliutil.WithNetwork(cmd.Context(), func(ctx context.Context, daemonClient daemon.DaemonClient) error {
 		return cliutil.WithConnector(ctx, func(ctx context.Context, connectorClient connector.ConnectorClient) error {
-			didConnect, connInfo, err := connect(ctx, connectorClient, cmd.OutOrStdout(), request)
+			didConnect, connInfo, err := connect(ctx, connectorClient, cmd.OutOrStdout(), request, implicitRequest)
 			if err != nil {
 				return err
 			}
@@ -74,7 +92,9 @@ func addKubeconfigEnv(cr *connector.ConnectRequest) {
 	}
 }
 
-func connect(ctx context.Context, connectorClient connector.ConnectorClient, stdout io.Writer, request *connector.ConnectRequest) (bool, *connector.ConnectInfo, error) {
+// connect makes the connector.Connect gRPC call using the given request. A nil request results in a
+// connector.Connect using the implicitRequest when the connector isn't already connected.
+func connect(ctx context.Context, connectorClient connector.ConnectorClient, stdout io.Writer, request, implicitRequest *connector.ConnectRequest) (bool, *connector.ConnectInfo, error) {
 	var ci *connector.ConnectInfo
 	var err error
 	if request == nil {
@@ -88,6 +108,12 @@ func connect(ctx context.Context, connectorClient connector.ConnectorClient, std
 		return false, nil, err
 	}
 
+	// Intercepts that couldn't be recreated after the session switched context or namespace must be
+	// reported regardless of the outcome of the connect.
+	for _, ii := range ci.DroppedIntercepts {
+		fmt.Fprintf(stdout, "Intercept %s in namespace %s was removed when switching session\n", ii.Spec.Name, ii.Spec.Namespace)
+	}
+
 	var msg string
 	cat := errcat.Unknown
 	switch ci.Error {
@@ -102,9 +128,9 @@ func connect(ctx context.Context, connectorClient connector.ConnectorClient, std
 		}
 		// The attempt is implicit, i.e. caused by direct invocation of another command without a
 		// prior call to connect. So we make it explicit here without flags
-		return connect(ctx, connectorClient, stdout, &connector.ConnectRequest{})
+		return connect(ctx, connectorClient, stdout, implicitRequest, nil)
 	case connector.ConnectInfo_MUST_RESTART:
-		msg = "Cluster configuration changed, please quit telepresence and reconnect"
+		msg = "Cluster configuration changed, please quit telepresence and reconnect, or use connect --switch-context or --switch-namespace"
 	case connector.ConnectInfo_TRAFFIC_MANAGER_FAILED, connector.ConnectInfo_CLUSTER_FAILED, connector.ConnectInfo_DAEMON_FAILED:
 		msg = ci.ErrorText
 		if ci.ErrorCategory != 0 {
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func Test_kubeFlagMap(t *testing.T) {
+	kubeFlags := pflag.NewFlagSet("", 0)
+	genericclioptions.NewConfigFlags(false).AddFlags(kubeFlags)
+	require.NoError(t, kubeFlags.Set("kubeconfig", "kubeconfig.yaml"))
+	require.NoError(t, kubeFlags.Set("context", "dev"))
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"kubeconfig": filepath.Join(wd, "kubeconfig.yaml"),
+		"context":    "dev",
+	}, kubeFlagMap(kubeFlags))
+}
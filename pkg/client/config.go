@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -20,6 +21,7 @@ import (
 	"github.com/datawire/dlib/dlog"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
 	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
 )
 
@@ -35,6 +37,7 @@ type Config struct {
 	TelepresenceAPI TelepresenceAPI `json:"telepresenceAPI,omitempty" yaml:"telepresenceAPI,omitempty"`
 	Daemons         Daemons         `json:"daemons,omitempty" yaml:"daemons,omitempty"`
 	Intercept       Intercept       `json:"intercept,omitempty" yaml:"intercept,omitempty"`
+	Cluster         Cluster         `json:"cluster,omitempty" yaml:"cluster,omitempty"`
 }
 
 // Merge merges this instance with the non-zero values of the given argument. The argument values take priority.
@@ -47,6 +50,7 @@ func (c *Config) Merge(o *Config) {
 	c.TelepresenceAPI.merge(&o.TelepresenceAPI)
 	c.Daemons.merge(&o.Daemons)
 	c.Intercept.merge(&o.Intercept)
+	c.Cluster.merge(&o.Cluster)
 }
 
 // Watch uses a file system watcher that receives events when the configuration changes
@@ -129,6 +133,8 @@ func (c *Config) UnmarshalYAML(node *yaml.Node) error {
 			err = ms[i+1].Decode(&c.Daemons)
 		case kv == "intercept":
 			err = ms[i+1].Decode(&c.Intercept)
+		case kv == "cluster":
+			err = ms[i+1].Decode(&c.Cluster)
 		case parseContext != nil:
 			dlog.Warn(parseContext, withLoc(fmt.Sprintf("unknown key %q", kv), ms[i]))
 		}
@@ -188,6 +194,7 @@ type timeoutContext struct {
 	context.Context
 	timeoutID  TimeoutID
 	timeoutVal time.Duration
+	flagName   string
 }
 
 func (ctx timeoutContext) Err() error {
@@ -197,39 +204,42 @@ func (ctx timeoutContext) Err() error {
 			timeoutID:  ctx.timeoutID,
 			timeoutVal: ctx.timeoutVal,
 			configFile: GetConfigFile(ctx),
+			flagName:   ctx.flagName,
 			err:        err,
 		}
 	}
 	return err
 }
 
-func (t *Timeouts) Get(timeoutID TimeoutID) time.Duration {
-	var timeoutVal time.Duration
+func (t *Timeouts) field(timeoutID TimeoutID) *time.Duration {
 	switch timeoutID {
 	case TimeoutAgentInstall:
-		timeoutVal = t.PrivateAgentInstall
+		return &t.PrivateAgentInstall
 	case TimeoutApply:
-		timeoutVal = t.PrivateApply
+		return &t.PrivateApply
 	case TimeoutClusterConnect:
-		timeoutVal = t.PrivateClusterConnect
+		return &t.PrivateClusterConnect
 	case TimeoutEndpointDial:
-		timeoutVal = t.PrivateEndpointDial
+		return &t.PrivateEndpointDial
 	case TimeoutHelm:
-		timeoutVal = t.PrivateHelm
+		return &t.PrivateHelm
 	case TimeoutIntercept:
-		timeoutVal = t.PrivateIntercept
+		return &t.PrivateIntercept
 	case TimeoutProxyDial:
-		timeoutVal = t.PrivateProxyDial
+		return &t.PrivateProxyDial
 	case TimeoutRoundtripLatency:
-		timeoutVal = t.PrivateRoundtripLatency
+		return &t.PrivateRoundtripLatency
 	case TimeoutTrafficManagerAPI:
-		timeoutVal = t.PrivateTrafficManagerAPI
+		return &t.PrivateTrafficManagerAPI
 	case TimeoutTrafficManagerConnect:
-		timeoutVal = t.PrivateTrafficManagerConnect
+		return &t.PrivateTrafficManagerConnect
 	default:
 		panic("should not happen")
 	}
-	return timeoutVal
+}
+
+func (t *Timeouts) Get(timeoutID TimeoutID) time.Duration {
+	return *t.field(timeoutID)
 }
 
 func (t *Timeouts) TimeoutContext(ctx context.Context, timeoutID TimeoutID) (context.Context, context.CancelFunc) {
@@ -239,14 +249,47 @@ func (t *Timeouts) TimeoutContext(ctx context.Context, timeoutID TimeoutID) (con
 		Context:    ctx,
 		timeoutID:  timeoutID,
 		timeoutVal: timeoutVal,
+		flagName:   timeoutFlagName(ctx, timeoutID),
 	}
 	return ctx, cancel
 }
 
+// WithTimeoutFlag returns a context with a copy of the current Config where the given timeouts have been
+// overridden with the value of the command line flag with the given name. Errors caused by those timeouts will
+// then refer to the flag rather than to the config file. The context is returned unchanged when the value is zero.
+func WithTimeoutFlag(ctx context.Context, flagName string, timeoutVal time.Duration, timeoutIDs ...TimeoutID) context.Context {
+	if timeoutVal <= 0 {
+		return ctx
+	}
+	cfg := *GetConfig(ctx)
+	oldFlags, _ := ctx.Value(timeoutFlagsKey{}).(map[TimeoutID]string)
+	flags := make(map[TimeoutID]string, len(oldFlags)+len(timeoutIDs))
+	for id, name := range oldFlags {
+		flags[id] = name
+	}
+	for _, id := range timeoutIDs {
+		*cfg.Timeouts.field(id) = timeoutVal
+		flags[id] = flagName
+	}
+	ctx = context.WithValue(ctx, timeoutFlagsKey{}, flags)
+	return WithConfig(ctx, &cfg)
+}
+
+// timeoutFlagsKey is the context key for the names of the command line flags that were used to override timeouts.
+type timeoutFlagsKey struct{}
+
+func timeoutFlagName(ctx context.Context, timeoutID TimeoutID) string {
+	if flags, ok := ctx.Value(timeoutFlagsKey{}).(map[TimeoutID]string); ok {
+		return flags[timeoutID]
+	}
+	return ""
+}
+
 type timeoutErr struct {
 	timeoutID  TimeoutID
 	timeoutVal time.Duration
 	configFile string
+	flagName   string
 	err        error
 }
 
@@ -286,6 +329,10 @@ func (e timeoutErr) Error() string {
 	default:
 		panic("should not happen")
 	}
+	if e.flagName != "" {
+		return fmt.Sprintf("the %s timed out.  The current timeout %s was set using the --%s flag",
+			humanName, e.timeoutVal, e.flagName)
+	}
 	return fmt.Sprintf("the %s timed out.  The current timeout %s can be configured as %q in %q",
 		humanName, e.timeoutVal, "timeouts."+yamlName, e.configFile)
 }
@@ -798,6 +845,86 @@ func (ic Intercept) MarshalYAML() (any, error) {
 	return im, nil
 }
 
+// Cluster contains defaults for the cluster connection. Command line flags take priority over these.
+type Cluster struct {
+	// DefaultNamespace is used when neither the --namespace flag nor the kubeconfig context specifies a namespace.
+	DefaultNamespace string `json:"defaultNamespace,omitempty" yaml:"defaultNamespace,omitempty"`
+
+	// NeverProxy are subnets that the root daemon will never route to the cluster. They are added to the
+	// never-proxy subnets of the kubeconfig extension.
+	NeverProxy []*iputil.Subnet `json:"neverProxy,omitempty" yaml:"neverProxy,omitempty"`
+}
+
+// UnmarshalYAML parses the cluster YAML
+func (cl *Cluster) UnmarshalYAML(node *yaml.Node) (err error) {
+	if node.Kind != yaml.MappingNode {
+		return errors.New(withLoc("cluster must be an object", node))
+	}
+
+	ms := node.Content
+	top := len(ms)
+	for i := 0; i < top; i += 2 {
+		kv, err := stringKey(ms[i])
+		if err != nil {
+			return err
+		}
+		v := ms[i+1]
+		switch kv {
+		case "defaultNamespace":
+			cl.DefaultNamespace = v.Value
+		case "neverProxy":
+			var subnets []string
+			if err := v.Decode(&subnets); err != nil {
+				return errors.New(withLoc("neverProxy must be a list of subnets", v))
+			}
+			cl.NeverProxy = nil
+			for _, sn := range subnets {
+				_, ipNet, err := net.ParseCIDR(sn)
+				if err != nil {
+					dlog.Warningf(parseContext, "unable to parse subnet %q: %v", sn, withLoc(err.Error(), v))
+					continue
+				}
+				cl.NeverProxy = append(cl.NeverProxy, (*iputil.Subnet)(ipNet))
+			}
+		default:
+			if parseContext != nil {
+				dlog.Warn(parseContext, withLoc(fmt.Sprintf("unknown key %q", kv), ms[i]))
+			}
+		}
+	}
+	return nil
+}
+
+func (cl *Cluster) merge(o *Cluster) {
+	if o.DefaultNamespace != "" {
+		cl.DefaultNamespace = o.DefaultNamespace
+	}
+	if len(o.NeverProxy) > 0 {
+		cl.NeverProxy = o.NeverProxy
+	}
+}
+
+// IsZero controls whether this element will be included in marshalled output
+func (cl Cluster) IsZero() bool {
+	return cl.DefaultNamespace == "" && len(cl.NeverProxy) == 0
+}
+
+// MarshalYAML is not using pointer receiver here, because Cluster is not pointer in the Config struct
+func (cl Cluster) MarshalYAML() (any, error) {
+	cm := make(map[string]any)
+	if cl.DefaultNamespace != "" {
+		cm["defaultNamespace"] = cl.DefaultNamespace
+	}
+	if len(cl.NeverProxy) > 0 {
+		subnets := make([]string, len(cl.NeverProxy))
+		for i, sn := range cl.NeverProxy {
+			subnets[i] = (*net.IPNet)(sn).String()
+		}
+		cm["neverProxy"] = subnets
+	}
+	return cm, nil
+}
+
 var parseContext context.Context
 
 type parsedFile struct{}
@@ -869,6 +996,7 @@ func GetDefaultConfig() Config {
 		Intercept: Intercept{
 			DefaultPort: defaultInterceptDefaultPort,
 		},
+		Cluster: Cluster{},
 	}
 }
 
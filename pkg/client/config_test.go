@@ -1,6 +1,8 @@
 package client
 
 import (
+	"context"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
@@ -14,6 +16,7 @@ import (
 
 	"github.com/datawire/dlib/dlog"
 	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
 	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
 )
 
@@ -46,6 +49,11 @@ telepresenceAPI:
 intercept:
   appProtocolStrategy: portName
   defaultPort: 9080
+cluster:
+  defaultNamespace: my-namespace
+  neverProxy:
+  - 10.0.0.0/16
+  - not-a-subnet
 `,
 	}
 
@@ -84,6 +92,9 @@ intercept:
 	assert.Equal(t, 1234, cfg.TelepresenceAPI.Port)                                            // from user
 	assert.Equal(t, k8sapi.PortName, cfg.Intercept.AppProtocolStrategy)                        // from user
 	assert.Equal(t, 9080, cfg.Intercept.DefaultPort)                                           // from user
+	assert.Equal(t, "my-namespace", cfg.Cluster.DefaultNamespace)                              // from user
+	require.Len(t, cfg.Cluster.NeverProxy, 1)                                                  // invalid subnet skipped
+	assert.Equal(t, "10.0.0.0/16", (*net.IPNet)(cfg.Cluster.NeverProxy[0]).String())           // from user
 }
 
 func Test_ConfigMarshalYAML(t *testing.T) {
@@ -100,6 +111,9 @@ func Test_ConfigMarshalYAML(t *testing.T) {
 	cfg.TelepresenceAPI.Port = 4567
 	cfg.Intercept.AppProtocolStrategy = k8sapi.PortName
 	cfg.Intercept.DefaultPort = 9080
+	cfg.Cluster.DefaultNamespace = "my-namespace"
+	_, ipNet, _ := net.ParseCIDR("192.168.0.0/24")
+	cfg.Cluster.NeverProxy = []*iputil.Subnet{(*iputil.Subnet)(ipNet)}
 	cfgBytes, err := yaml.Marshal(cfg)
 	require.NoError(t, err)
 
@@ -119,3 +133,32 @@ func Test_ConfigMarshalYAMLDefaults(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "{}\n", string(cfgBytes))
 }
+
+func TestWithTimeoutFlag(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	cfg := GetDefaultConfig()
+	ctx = WithConfig(ctx, &cfg)
+
+	assert.Equal(t, ctx, WithTimeoutFlag(ctx, "connect-timeout", 0, TimeoutClusterConnect))
+
+	fc := WithTimeoutFlag(ctx, "connect-timeout", 10*time.Millisecond, TimeoutClusterConnect, TimeoutHelm)
+	fc = WithTimeoutFlag(fc, "intercept-timeout", 3*time.Minute, TimeoutIntercept)
+	tos := &GetConfig(fc).Timeouts
+	assert.Equal(t, 10*time.Millisecond, tos.Get(TimeoutClusterConnect))
+	assert.Equal(t, 10*time.Millisecond, tos.Get(TimeoutHelm))
+	assert.Equal(t, 3*time.Minute, tos.Get(TimeoutIntercept))
+	assert.Equal(t, defaultTimeoutsTrafficManagerConnect, tos.Get(TimeoutTrafficManagerConnect))
+
+	// The original config is unaffected
+	assert.Equal(t, defaultTimeoutsClusterConnect, GetConfig(ctx).Timeouts.Get(TimeoutClusterConnect))
+
+	tc, cancel := tos.TimeoutContext(fc, TimeoutHelm)
+	defer cancel()
+	<-tc.Done()
+	assert.ErrorIs(t, tc.Err(), context.DeadlineExceeded)
+	assert.Equal(t, "the helm operation timed out.  The current timeout 10ms was set using the --connect-timeout flag", tc.Err().Error())
+
+	tc, cancel = tos.TimeoutContext(fc, TimeoutTrafficManagerConnect)
+	defer cancel()
+	assert.NoError(t, tc.Err())
+}
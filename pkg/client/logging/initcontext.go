@@ -3,6 +3,7 @@ package logging
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -20,7 +21,8 @@ import (
 // loggerForTest exposes internals to initcontext_test.go
 var loggerForTest *logrus.Logger
 
-// InitContext sets up standard Telepresence logging for a background process
+// InitContext sets up standard Telepresence logging for a background process. The log level and format
+// of the Options found in the context, if any, take precedence over the config.
 func InitContext(ctx context.Context, name string, strategy RotationStrategy, captureStd bool) (context.Context, error) {
 	logger := logrus.New()
 	loggerForTest = logger
@@ -29,10 +31,15 @@ func InitContext(ctx context.Context, name string, strategy RotationStrategy, ca
 	logger.SetLevel(logrus.InfoLevel)
 	logger.ReportCaller = false // turned on when level >= logrus.TraceLevel
 
+	opts := GetOptions(ctx)
+	if opts == nil {
+		opts = &Options{}
+	}
+	var tsFormat string
 	if captureStd && IsTerminal(int(os.Stdout.Fd())) {
-		logger.Formatter = log.NewFormatter("15:04:05.0000")
+		tsFormat = "15:04:05.0000"
 	} else {
-		logger.Formatter = log.NewFormatter("2006-01-02 15:04:05.0000")
+		tsFormat = "2006-01-02 15:04:05.0000"
 		dir, err := filelocation.AppUserLogDir(ctx)
 		if err != nil {
 			return ctx, err
@@ -51,7 +58,13 @@ func InitContext(ctx context.Context, name string, strategy RotationStrategy, ca
 		}
 		logger.SetOutput(rf)
 	}
+	format := opts.Format
+	if format == "" {
+		format = log.FormatText
+	}
+	log.SetLogrusFormat(logger, format, name, tsFormat)
 	ctx = dlog.WithLogger(ctx, dlog.WrapLogrus(logger))
+	ctx = log.WithFormatSetter(ctx, logger, name, tsFormat)
 
 	// Read the config and set the configured level.
 	logLevels := client.GetConfig(ctx).LogLevels
@@ -61,7 +74,11 @@ func InitContext(ctx context.Context, name string, strategy RotationStrategy, ca
 	} else if name == "connector" {
 		level = logLevels.UserDaemon
 	}
-	log.SetLogrusLevel(logger, level.String())
+	if opts.Level != "" {
+		log.SetLogrusLevel(logger, opts.Level)
+	} else {
+		log.SetLogrusLevel(logger, level.String())
+	}
 	ctx = log.WithLevelSetter(ctx, logger)
 	return ctx, nil
 }
@@ -84,15 +101,29 @@ func SummarizeLog(ctx context.Context, name string) (string, error) {
 	for scanner.Scan() {
 		// XXX: is there a better way to detect error lines?
 		txt := scanner.Text()
-		parts := strings.Fields(txt)
-		if len(parts) < 3 {
-			continue
+		var level, msg string
+		if strings.HasPrefix(txt, "{") {
+			// Written using --log-format json
+			var entry struct {
+				Level string `json:"level"`
+				Msg   string `json:"msg"`
+			}
+			if json.Unmarshal([]byte(txt), &entry) != nil {
+				continue
+			}
+			level, msg = entry.Level, entry.Msg
+		} else {
+			parts := strings.Fields(txt)
+			if len(parts) < 3 {
+				continue
+			}
+			level, msg = parts[2], txt
 		}
-		switch parts[2] {
+		switch level {
 		case "error":
 			errorCount++
 		case "info":
-			if strings.Contains(txt, "-- Starting new session") {
+			if strings.Contains(msg, "-- Starting new session") {
 				// Start over. No use counting errors from previous sessions
 				errorCount = 0
 			}
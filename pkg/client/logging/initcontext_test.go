@@ -2,11 +2,13 @@ package logging
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/datawire/dlib/dtime"
 	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+	"github.com/telepresenceio/telepresence/v2/pkg/log"
 )
 
 type dtimeHook struct{}
@@ -237,4 +240,58 @@ func TestInitContext(t *testing.T) {
 		check.NoError(err)
 		check.Equal(maxFiles, len(files))
 	})
+
+	t.Run("json format", func(t *testing.T) {
+		ctx, _, logFile := testSetup(t)
+		check := require.New(t)
+
+		ctx = WithOptions(ctx, &Options{Level: "debug", Format: log.FormatJSON})
+		c, err := InitContext(ctx, logName, NewRotateOnce(), true)
+		loggerForTest.AddHook(&dtimeHook{})
+		check.NoError(err)
+		c = dlog.WithField(c, log.SessionIDKey, "abc123")
+		dlog.Debug(dlog.WithField(c, log.InterceptKey, "echo"), "debug message")
+		dlog.Error(c, "error message")
+		closeLog(t)
+
+		bs, err := os.ReadFile(logFile)
+		check.NoError(err)
+		var entries []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(string(bs)), "\n") {
+			var entry map[string]any
+			check.NoError(json.Unmarshal([]byte(line), &entry), line)
+			entries = append(entries, entry)
+		}
+		// The first entry is the "Logging at this level" message
+		check.Len(entries, 3)
+		entries = entries[1:]
+		check.Equal("debug", entries[0]["level"])
+		check.Equal("debug message", entries[0]["msg"])
+		check.Equal(logName, entries[0][log.ComponentKey])
+		check.Equal("abc123", entries[0][log.SessionIDKey])
+		check.Equal("echo", entries[0][log.InterceptKey])
+		check.Equal("error", entries[1]["level"])
+		check.NotContains(entries[1], log.InterceptKey)
+
+		summary, err := SummarizeLog(ctx, logName)
+		check.NoError(err)
+		check.Contains(summary, "1 error found")
+	})
+
+	t.Run("text format omits structured keys", func(t *testing.T) {
+		ctx, _, logFile := testSetup(t)
+		check := require.New(t)
+
+		c, err := InitContext(ctx, logName, NewRotateOnce(), true)
+		loggerForTest.AddHook(&dtimeHook{})
+		check.NoError(err)
+		c = dlog.WithField(c, log.SessionIDKey, "abc123")
+		dlog.Info(dlog.WithField(c, "other", "value"), "info message")
+		closeLog(t)
+
+		bs, err := os.ReadFile(logFile)
+		check.NoError(err)
+		infoTs := dtime.Now().Format("2006-01-02 15:04:05.0000")
+		check.Contains(string(bs), fmt.Sprintf("%s info    info message : other=\"value\"\n", infoTs))
+	})
 }
@@ -0,0 +1,126 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/log"
+)
+
+// Options are the log level and log format given on the command line. A non-empty level takes
+// precedence over the levels in the config.yml. An empty format means log.FormatText.
+type Options struct {
+	Level  string
+	Format string
+}
+
+type optionsKey struct{}
+
+// WithOptions returns a context that InitContext will use to find the options. The options can be
+// updated using ApplyOptions after InitContext has been called.
+func WithOptions(ctx context.Context, opts *Options) context.Context {
+	return context.WithValue(ctx, optionsKey{}, opts)
+}
+
+// GetOptions returns the options of the given context, or nil if the context has no options.
+func GetOptions(ctx context.Context) *Options {
+	opts, _ := ctx.Value(optionsKey{}).(*Options)
+	return opts
+}
+
+// ApplyOptions stores the given options in the context and applies them to the logger that was created
+// by InitContext.
+func ApplyOptions(ctx context.Context, opts Options) {
+	if cp := GetOptions(ctx); cp != nil {
+		*cp = opts
+	}
+	if opts.Format != "" {
+		log.SetFormat(ctx, opts.Format)
+	}
+	if opts.Level != "" {
+		log.SetLevel(ctx, opts.Level)
+	}
+}
+
+// Args returns the command line flags that pass the options on to a daemon process.
+func (o *Options) Args() []string {
+	var args []string
+	if o == nil {
+		return args
+	}
+	if o.Level != "" {
+		args = append(args, "--log-level", o.Level)
+	}
+	if o.Format != "" {
+		args = append(args, "--log-format", o.Format)
+	}
+	return args
+}
+
+// AddFlags adds the --log-level and --log-format flags to the given flag set. The values are validated
+// when the flags are parsed.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Var(&levelValue{}, "log-level", `set the log level, one of "trace", "debug", "info", "warn", or "error"`)
+	flags.Var(&formatValue{}, "log-format", `set the log format, "text" or "json"`)
+}
+
+// OptionsFromFlags returns the options given by the flags that were added using AddFlags.
+func OptionsFromFlags(flags *pflag.FlagSet) Options {
+	var opts Options
+	if f := flags.Lookup("log-level"); f != nil {
+		opts.Level = f.Value.String()
+	}
+	if f := flags.Lookup("log-format"); f != nil {
+		opts.Format = f.Value.String()
+	}
+	return opts
+}
+
+type levelValue struct {
+	level string
+}
+
+func (v *levelValue) Set(s string) error {
+	s = strings.ToLower(s)
+	switch s {
+	case "trace", "debug", "info", "warn", "warning", "error":
+		lv, _ := logrus.ParseLevel(s)
+		v.level = lv.String()
+		return nil
+	default:
+		return fmt.Errorf(`unsupported log level %q, must be one of "trace", "debug", "info", "warn", or "error"`, s)
+	}
+}
+
+func (v *levelValue) String() string {
+	return v.level
+}
+
+func (v *levelValue) Type() string {
+	return "string"
+}
+
+type formatValue struct {
+	format string
+}
+
+func (v *formatValue) Set(s string) error {
+	s = strings.ToLower(s)
+	if err := log.ValidateFormat(s); err != nil {
+		return err
+	}
+	v.format = s
+	return nil
+}
+
+func (v *formatValue) String() string {
+	return v.format
+}
+
+func (v *formatValue) Type() string {
+	return "string"
+}
@@ -0,0 +1,40 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptionsFromFlags(t *testing.T) {
+	parse := func(args ...string) (Options, error) {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		AddFlags(flags)
+		if err := flags.Parse(args); err != nil {
+			return Options{}, err
+		}
+		return OptionsFromFlags(flags), nil
+	}
+
+	opts, err := parse()
+	require.NoError(t, err)
+	assert.Equal(t, Options{}, opts)
+	assert.Empty(t, opts.Args())
+
+	opts, err = parse("--log-level", "DEBUG", "--log-format", "json")
+	require.NoError(t, err)
+	assert.Equal(t, Options{Level: "debug", Format: "json"}, opts)
+	assert.Equal(t, []string{"--log-level", "debug", "--log-format", "json"}, opts.Args())
+
+	opts, err = parse("--log-level", "warn")
+	require.NoError(t, err)
+	assert.Equal(t, "warning", opts.Level)
+
+	_, err = parse("--log-level", "loud")
+	assert.ErrorContains(t, err, `unsupported log level "loud"`)
+
+	_, err = parse("--log-format", "xml")
+	assert.ErrorContains(t, err, `unsupported log format "xml"`)
+}
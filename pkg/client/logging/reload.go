@@ -10,7 +10,7 @@ import (
 
 // ReloadDaemonConfig replaces the current config with one loaded from disk and
 // calls SetLevel with the log level defined for the rootDaemon or userDaemon
-// depending on the root flag
+// depending on the root flag, unless a level was given on the command line
 func ReloadDaemonConfig(c context.Context, root bool) error {
 	newCfg, err := client.LoadConfig(c)
 	if err != nil {
@@ -18,7 +18,9 @@ func ReloadDaemonConfig(c context.Context, root bool) error {
 	}
 	client.ReplaceConfig(c, newCfg)
 	var level string
-	if root {
+	if opts := GetOptions(c); opts != nil && opts.Level != "" {
+		level = opts.Level
+	} else if root {
 		level = newCfg.LogLevels.RootDaemon.String()
 	} else {
 		level = newCfg.LogLevels.UserDaemon.String()
@@ -74,6 +74,12 @@ type Server struct {
 
 	// Function that sends a lookup requrest to the traffic-manager
 	clusterLookup func(context.Context, string) ([][]byte, error)
+
+	// searchDomains are the configured search domains, added to the search path of every update
+	searchDomains []string
+
+	// resolvers are the upstream resolvers that names in configured domains are sent to
+	resolvers []*upstreamResolver
 }
 
 type cacheEntry struct {
@@ -108,6 +114,26 @@ func NewServer(config *rpc.DNSConfig, clusterLookup func(context.Context, string
 	if config.LookupTimeout.AsDuration() <= 0 {
 		config.LookupTimeout = durationpb.New(8 * time.Second)
 	}
+
+	// Names in search domains and in domains that have a resolver must always be resolved in the cluster,
+	// so they are added to the include suffixes. A search domain without dots is a namespace.
+	var searchDomains []string
+	for _, sd := range config.SearchDomains {
+		if sd = normalizeDomain(sd); sd == "" {
+			continue
+		}
+		if ns := sd[:len(sd)-1]; !strings.ContainsRune(ns, '.') {
+			searchDomains = append(searchDomains, ns)
+		} else {
+			searchDomains = append(searchDomains, sd)
+			config.IncludeSuffixes = append(config.IncludeSuffixes, "."+ns)
+		}
+	}
+	for domain := range config.Resolvers {
+		if d := normalizeDomain(domain); d != "" {
+			config.IncludeSuffixes = append(config.IncludeSuffixes, "."+d[:len(d)-1])
+		}
+	}
 	s := &Server{
 		config:        config,
 		namespaces:    make(map[string]struct{}),
@@ -116,6 +142,7 @@ func NewServer(config *rpc.DNSConfig, clusterLookup func(context.Context, string
 		searchPathCh:  make(chan []string, 5),
 		clusterDomain: defaultClusterDomain,
 		clusterLookup: clusterLookup,
+		searchDomains: searchDomains,
 	}
 	s.cacheResolve = s.resolveWithRecursionCheck
 	return s
@@ -195,6 +222,10 @@ func (s *Server) resolveInCluster(c context.Context, query string) (results []ne
 		return nil, nil
 	}
 
+	if ips, ok := s.resolveUpstream(c, query); ok {
+		return ips, nil
+	}
+
 	// Give the cluster lookup a reasonable timeout.
 	c, cancel := context.WithTimeout(c, s.config.LookupTimeout.AsDuration())
 	defer cancel()
@@ -220,6 +251,8 @@ func (s *Server) GetConfig() *rpc.DNSConfig {
 		dnsConfig.ExcludeSuffixes = s.config.ExcludeSuffixes
 		dnsConfig.IncludeSuffixes = s.config.IncludeSuffixes
 		dnsConfig.LookupTimeout = s.config.LookupTimeout
+		dnsConfig.SearchDomains = s.config.SearchDomains
+		dnsConfig.Resolvers = s.config.Resolvers
 	}
 	return dnsConfig
 }
@@ -240,6 +273,7 @@ func (s *Server) SetSearchPath(ctx context.Context, paths, namespaces []string)
 	for _, ns := range namespaces {
 		paths = append(paths, ns+".svc."+s.clusterDomain)
 	}
+	paths = append(paths, s.searchDomains...)
 	select {
 	case <-ctx.Done():
 	case s.searchPathCh <- paths:
@@ -589,6 +623,7 @@ func (s *Server) Run(c context.Context, initDone chan<- struct{}, listeners []ne
 	s.ctx = c
 	s.fallbackPool = fallbackPool
 	s.resolve = resolve
+	s.resolvers = parseResolvers(c, s.config.Resolvers)
 
 	g := dgroup.NewGroup(c, dgroup.GroupConfig{})
 	for _, listener := range listeners {
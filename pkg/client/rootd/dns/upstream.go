@@ -0,0 +1,151 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
+)
+
+// upstreamRetryInterval is the time that a failing upstream resolver is bypassed before it's tried again.
+const upstreamRetryInterval = 30 * time.Second
+
+// upstreamResolver is a DNS server that names in a configured domain are sent to.
+type upstreamResolver struct {
+	domain  string // lower case, without leading dot but with a trailing dot
+	addr    string // <ip>:<port>
+	failing int32  // 1 when the last lookup failed, accessed atomically
+	retryAt int64  // UnixNano time when a failing resolver is tried again, accessed atomically
+}
+
+// normalizeDomain returns the given domain in lower case, without leading dot and with a trailing dot.
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.Trim(strings.TrimSpace(domain), "."))
+	if domain == "" {
+		return ""
+	}
+	return domain + "."
+}
+
+// resolverAddr parses an address in the form "<ip>[:<port>]" into "<ip>:<port>", using port 53 by default.
+func resolverAddr(addr string) (string, error) {
+	if ip := iputil.Parse(addr); ip != nil {
+		return net.JoinHostPort(ip.String(), "53"), nil
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	ip := iputil.Parse(host)
+	if ip == nil {
+		return "", fmt.Errorf("%q is not an IP address", host)
+	}
+	if pn, err := strconv.ParseUint(port, 10, 16); err != nil || pn == 0 {
+		return "", fmt.Errorf("%q is not a valid port", port)
+	}
+	return net.JoinHostPort(ip.String(), port), nil
+}
+
+// parseResolvers parses the given map of domain to resolver address. Entries that cannot be parsed are
+// logged and skipped, which means that names in their domain are resolved in the cluster. The result is
+// sorted so that more specific domains come first.
+func parseResolvers(c context.Context, resolvers map[string]string) []*upstreamResolver {
+	urs := make([]*upstreamResolver, 0, len(resolvers))
+	for domain, addr := range resolvers {
+		d := normalizeDomain(domain)
+		if d == "" {
+			dlog.Warnf(c, "ignoring DNS resolver %q because it has no domain", addr)
+			continue
+		}
+		a, err := resolverAddr(addr)
+		if err != nil {
+			dlog.Warnf(c, "ignoring DNS resolver %q for domain %s: %v", addr, d, err)
+			continue
+		}
+		urs = append(urs, &upstreamResolver{domain: d, addr: a})
+	}
+	sort.Slice(urs, func(i, j int) bool {
+		if li, lj := len(urs[i].domain), len(urs[j].domain); li != lj {
+			return li > lj
+		}
+		return urs[i].domain < urs[j].domain
+	})
+	return urs
+}
+
+// upstreamResolverFor returns the resolver for the most specific domain that the given query belongs
+// to, or nil if there is no such resolver.
+func (s *Server) upstreamResolverFor(query string) *upstreamResolver {
+	for _, ur := range s.resolvers {
+		if query == ur.domain || strings.HasSuffix(query, "."+ur.domain) {
+			return ur
+		}
+	}
+	return nil
+}
+
+// lookup sends A and AAAA queries for the given name to the resolver. A nil slice and a nil error is
+// returned when the name doesn't exist.
+func (ur *upstreamResolver) lookup(c context.Context, name string, timeout time.Duration) ([]net.IP, error) {
+	dc := &dns.Client{Net: "udp", Timeout: timeout}
+	var ips []net.IP
+	for _, qType := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		q := new(dns.Msg)
+		q.SetQuestion(name, qType)
+		r, _, err := dc.ExchangeContext(c, q, ur.addr)
+		if err != nil {
+			return nil, err
+		}
+		switch r.Rcode {
+		case dns.RcodeSuccess:
+		case dns.RcodeNameError:
+			return nil, nil
+		default:
+			return nil, fmt.Errorf("%s responded with %s", ur.addr, dns.RcodeToString[r.Rcode])
+		}
+		for _, rr := range r.Answer {
+			switch rr := rr.(type) {
+			case *dns.A:
+				ips = append(ips, rr.A)
+			case *dns.AAAA:
+				ips = append(ips, rr.AAAA)
+			}
+		}
+	}
+	return ips, nil
+}
+
+// resolveUpstream resolves the given query using its upstream resolver. The returned boolean is false when
+// there is no such resolver or when the resolver failed, in which case the query must be resolved in the
+// cluster. A failing resolver is logged once, and then again when it recovers. It's bypassed for the
+// upstreamRetryInterval after each failure.
+func (s *Server) resolveUpstream(c context.Context, query string) ([]net.IP, bool) {
+	ur := s.upstreamResolverFor(query)
+	if ur == nil {
+		return nil, false
+	}
+	if atomic.LoadInt32(&ur.failing) == 1 && time.Now().UnixNano() < atomic.LoadInt64(&ur.retryAt) {
+		return nil, false
+	}
+	ips, err := ur.lookup(c, query, s.config.LookupTimeout.AsDuration())
+	if err != nil {
+		atomic.StoreInt64(&ur.retryAt, time.Now().Add(upstreamRetryInterval).UnixNano())
+		if atomic.CompareAndSwapInt32(&ur.failing, 0, 1) {
+			dlog.Warnf(c, "DNS resolver %s for domain %s failed, resolving in the cluster instead: %v", ur.addr, ur.domain, err)
+		}
+		return nil, false
+	}
+	if atomic.CompareAndSwapInt32(&ur.failing, 1, 0) {
+		dlog.Infof(c, "DNS resolver %s for domain %s has recovered", ur.addr, ur.domain)
+	}
+	return ips, true
+}
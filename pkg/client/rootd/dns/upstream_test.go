@@ -0,0 +1,153 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/daemon"
+)
+
+// startUpstream starts a DNS server on localhost that answers A queries for names in the given map and
+// responds with NXDOMAIN for all other names. It returns the server's address.
+func startUpstream(t *testing.T, names map[string]net.IP) string {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	started := make(chan struct{})
+	srv := &dns.Server{
+		PacketConn:        pc,
+		NotifyStartedFunc: func() { close(started) },
+		Handler: dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+			m := new(dns.Msg)
+			m.SetReply(r)
+			q := r.Question[0]
+			if ip, ok := names[q.Name]; ok {
+				if q.Qtype == dns.TypeA {
+					m.Answer = append(m.Answer, &dns.A{
+						Hdr: dns.RR_Header{Name: q.Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+						A:   ip,
+					})
+				}
+			} else {
+				m.Rcode = dns.RcodeNameError
+			}
+			_ = w.WriteMsg(m)
+		}),
+	}
+	go func() { _ = srv.ActivateAndServe() }()
+	t.Cleanup(func() { _ = srv.Shutdown() })
+	<-started
+	return pc.LocalAddr().String()
+}
+
+func Test_parseResolvers(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	urs := parseResolvers(ctx, map[string]string{
+		"example.com":      "10.0.0.1",
+		"Corp.Example.com": "10.0.0.2:5353",
+		".":                "10.0.0.3",
+		"bad.example":      "not-an-ip",
+		"badport.example":  "10.0.0.4:xyz",
+		"ipv6.example":     "[fd00::1]:53",
+	})
+	require.Len(t, urs, 3)
+	assert.Equal(t, &upstreamResolver{domain: "corp.example.com.", addr: "10.0.0.2:5353"}, urs[0])
+	assert.Equal(t, &upstreamResolver{domain: "ipv6.example.", addr: "[fd00::1]:53"}, urs[1])
+	assert.Equal(t, &upstreamResolver{domain: "example.com.", addr: "10.0.0.1:53"}, urs[2])
+
+	s := &Server{resolvers: urs}
+	assert.Equal(t, urs[0], s.upstreamResolverFor("db.corp.example.com."))
+	assert.Equal(t, urs[2], s.upstreamResolverFor("www.example.com."))
+	assert.Equal(t, urs[2], s.upstreamResolverFor("example.com."))
+	assert.Nil(t, s.upstreamResolverFor("notexample.com."))
+	assert.Nil(t, s.upstreamResolverFor("echo.default."))
+}
+
+func TestNewServer_searchDomains(t *testing.T) {
+	s := NewServer(&rpc.DNSConfig{
+		SearchDomains: []string{"Corp.Example.com.", "other-ns", ""},
+		Resolvers:     map[string]string{"lab.example.org": "10.0.0.1"},
+	}, nil)
+	assert.Equal(t, []string{"corp.example.com.", "other-ns"}, s.searchDomains)
+	assert.Contains(t, s.config.IncludeSuffixes, ".corp.example.com")
+	assert.Contains(t, s.config.IncludeSuffixes, ".lab.example.org")
+
+	// Names in configured domains bypass the default exclude suffixes
+	assert.True(t, s.shouldDoClusterLookup("db.corp.example.com."))
+	assert.True(t, s.shouldDoClusterLookup("host.lab.example.org."))
+	assert.False(t, s.shouldDoClusterLookup("www.example.com."))
+}
+
+func TestServer_resolveUpstream(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	addr := startUpstream(t, map[string]net.IP{"db.corp.example.": net.IP{10, 1, 2, 3}})
+
+	var clusterQueries []string
+	clusterLookup := func(_ context.Context, name string) ([][]byte, error) {
+		clusterQueries = append(clusterQueries, name)
+		return [][]byte{{10, 9, 9, 9}}, nil
+	}
+	newServer := func(resolverAddr string) *Server {
+		s := NewServer(&rpc.DNSConfig{
+			Resolvers:     map[string]string{"corp.example": resolverAddr},
+			LookupTimeout: durationpb.New(500 * time.Millisecond),
+		}, clusterLookup)
+		s.resolvers = parseResolvers(ctx, s.config.Resolvers)
+		return s
+	}
+
+	t.Run("found", func(t *testing.T) {
+		clusterQueries = nil
+		ips, err := newServer(addr).resolveInCluster(ctx, "db.corp.example.")
+		require.NoError(t, err)
+		require.Len(t, ips, 1)
+		assert.True(t, ips[0].Equal(net.IP{10, 1, 2, 3}))
+		assert.Empty(t, clusterQueries)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		clusterQueries = nil
+		ips, err := newServer(addr).resolveInCluster(ctx, "nope.corp.example.")
+		require.NoError(t, err)
+		assert.Empty(t, ips)
+		assert.Empty(t, clusterQueries)
+	})
+
+	t.Run("other domain", func(t *testing.T) {
+		clusterQueries = nil
+		ips, err := newServer(addr).resolveInCluster(ctx, "echo.default.")
+		require.NoError(t, err)
+		require.Len(t, ips, 1)
+		assert.Equal(t, []string{"echo.default"}, clusterQueries)
+	})
+
+	t.Run("unreachable falls back", func(t *testing.T) {
+		// Reserve a port and then close it so that nothing answers on it
+		pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		deadAddr := pc.LocalAddr().String()
+		require.NoError(t, pc.Close())
+
+		clusterQueries = nil
+		s := newServer(deadAddr)
+		ips, err := s.resolveInCluster(ctx, "db.corp.example.")
+		require.NoError(t, err)
+		require.Len(t, ips, 1)
+		assert.True(t, ips[0].Equal(net.IP{10, 9, 9, 9}))
+		assert.Equal(t, []string{"db.corp.example"}, clusterQueries)
+
+		// The failing resolver is bypassed on the next query
+		start := time.Now()
+		_, err = s.resolveInCluster(ctx, "db.corp.example.")
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), 250*time.Millisecond)
+		assert.Len(t, clusterQueries, 2)
+	})
+}
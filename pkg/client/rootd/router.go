@@ -22,6 +22,8 @@ import (
 
 func (s *session) routerWorker(c context.Context) error {
 	dlog.Debug(c, "TUN read loop starting")
+	atomic.StoreInt32(&s.routerRunning, 1)
+	defer atomic.StoreInt32(&s.routerRunning, 0)
 
 	// bufCh is just a small buffer to enable better parallel processing between
 	// the actual TUN reader loop and the packet handlers.
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -71,16 +72,19 @@ type service struct {
 
 // Command returns the telepresence sub-command "daemon-foreground"
 func Command() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:    ProcessName + "-foreground <logging dir> <config dir>",
 		Short:  "Launch Telepresence " + titleName + " in the foreground (debug)",
 		Args:   cobra.ExactArgs(2),
 		Hidden: true,
 		Long:   help,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return run(cmd.Context(), args[0], args[1])
+			opts := logging.OptionsFromFlags(cmd.Flags())
+			return run(logging.WithOptions(cmd.Context(), &opts), args[0], args[1])
 		},
 	}
+	logging.AddFlags(cmd.Flags())
+	return cmd
 }
 
 func (d *service) Version(_ context.Context, _ *empty.Empty) (*common.VersionInfo, error) {
@@ -96,6 +100,8 @@ func (d *service) Status(_ context.Context, _ *empty.Empty) (*rpc.DaemonStatus,
 	r := &rpc.DaemonStatus{}
 	if d.session != nil {
 		r.OutboundConfig = d.session.getInfo()
+		r.RouterRunning = atomic.LoadInt32(&d.session.routerRunning) == 1
+		r.RoutingReady = r.RouterRunning && atomic.LoadInt32(&d.session.subnetsRouted) == 1
 	}
 	return r, nil
 }
@@ -255,7 +261,7 @@ nextSession:
 			session, reply.err = newSession(c, d.scout, oi)
 			if reply.err == nil {
 				d.session = session
-				d.sessionContext, session.cancel = context.WithCancel(c)
+				d.sessionContext, session.cancel = context.WithCancel(dlog.WithField(c, log.SessionIDKey, oi.Session.GetSessionId()))
 				reply.status = &rpc.DaemonStatus{OutboundConfig: d.session.getInfo()}
 			}
 		}
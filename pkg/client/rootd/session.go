@@ -85,6 +85,12 @@ type session struct {
 	// are obtained using a connpool.ConnID.
 	handlers *tunnel.Pool
 
+	// routerRunning is 1 while the routerWorker reads packets from the TUN device
+	routerRunning int32
+
+	// subnetsRouted is 1 once the subnets of the first cluster info have been routed to the TUN device
+	subnetsRouted int32
+
 	// fragmentMap is when concatenating ipv4 fragments
 	fragmentMap map[uint16][]*buffer.Data
 
@@ -443,7 +449,9 @@ func (s *session) onClusterInfo(ctx context.Context, mgrInfo *manager.ClusterInf
 	s.clusterSubnets = subnets
 	if err := s.refreshSubnets(ctx); err != nil {
 		dlog.Error(ctx, err)
+		return
 	}
+	atomic.StoreInt32(&s.subnetsRouted, 1)
 }
 
 func (s *session) checkConnectivity(ctx context.Context, info *manager.ClusterInfo) {
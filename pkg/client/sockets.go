@@ -24,6 +24,12 @@ func RemoveSocket(listener net.Listener) error {
 	return removeSocket(listener)
 }
 
+// RemoveStaleSocket removes the socket with the given name from the filesystem. The removal is
+// performed with elevated privileges when asRoot is true. It is not an error if the socket doesn't exist.
+func RemoveStaleSocket(ctx context.Context, name string, asRoot bool) error {
+	return removeStaleSocket(ctx, name, asRoot)
+}
+
 // SocketExists returns true if a socket is found with the given name
 func SocketExists(name string) (bool, error) {
 	return socketExists(name)
@@ -99,6 +99,24 @@ func removeSocket(listener net.Listener) error {
 	return os.Remove(listener.Addr().String())
 }
 
+func removeStaleSocket(ctx context.Context, path string, asRoot bool) error {
+	if asRoot && !proc.IsAdmin() {
+		cmd := proc.CommandContext(ctx, "sudo", "rm", "-f", path)
+		cmd.DisableLogging = true
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("sudo rm -f %s: %w", path, err)
+		}
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 // socketExists returns true if a socket is found at the given path
 func socketExists(path string) (bool, error) {
 	s, err := os.Stat(path)
@@ -66,6 +66,11 @@ func removeSocket(listener net.Listener) error {
 	return nil
 }
 
+// removeStaleSocket does nothing because a named pipe vanishes when the process that owns it exits
+func removeStaleSocket(_ context.Context, _ string, _ bool) error {
+	return nil
+}
+
 // socketExists returns true if a socket exists with the given name
 func socketExists(name string) (bool, error) {
 	uPath, err := windows.UTF16PtrFromString(name)
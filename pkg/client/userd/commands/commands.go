@@ -29,7 +29,8 @@ func GetCwd(ctx context.Context) string {
 // GetCommands will return all commands implemented by the connector daemon.
 func GetCommands() cliutil.CommandGroups {
 	return cliutil.CommandGroups{
-		"Tracing": []*cobra.Command{TraceCommand(), PushTraces()},
+		"Tracing": []*cobra.Command{TraceCommand(), PushTraces(), ExportTraces()},
+		"Agents":  []*cobra.Command{AgentsCommand()},
 	}
 }
 
@@ -37,13 +38,21 @@ func GetCommands() cliutil.CommandGroups {
 // the error given. Should be used to build help strings even if it's not possible to connect to the connector daemon.
 func GetCommandsForLocal(err error) cliutil.CommandGroups {
 	groups := GetCommands()
-	for _, cmds := range groups {
+	var setRunE func(cmds []*cobra.Command)
+	setRunE = func(cmds []*cobra.Command) {
 		for _, cmd := range cmds {
+			if cmd.HasSubCommands() {
+				setRunE(cmd.Commands())
+				continue
+			}
 			cmd.RunE = func(_ *cobra.Command, _ []string) error {
 				// err here will be ErrNoUserDaemon "telepresence user daemon is not running"
 				return fmt.Errorf("unable to run command: %w", err)
 			}
 		}
 	}
+	for _, cmds := range groups {
+		setRunE(cmds)
+	}
 	return groups
 }
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/spf13/cobra"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/tracing"
+)
+
+func ExportTraces() *cobra.Command {
+	var remotePort uint16
+	cmd := &cobra.Command{
+		Use:  "export-traces <file>",
+		Args: cobra.ExactArgs(1),
+
+		Short: "Export Traces",
+		Long: "Export the traces collected by the Telepresence components to a file for offline analysis.\n\n" +
+			"Each line of the file is an OTLP TracesData message, encoded using the standard protobuf JSON " +
+			"mapping. Note that this mapping encodes trace and span IDs using base64.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return exportTraces(cmd, remotePort, args[0])
+		},
+		Annotations: map[string]string{
+			CommandRequiresSession: "true",
+		},
+	}
+	cmd.Flags().Uint16VarP(&remotePort, "port", "p", 15766,
+		"The remote port where traffic manager and agent are exposing traces."+
+			"Corresponds to tracing.grpcPort in the helm chart values")
+	return cmd
+}
+
+func exportTraces(cmd *cobra.Command, remotePort uint16, destFile string) error {
+	ctx := cmd.Context()
+	tCh, errCh, err := launchTraceWriter(ctx, destFile, func(w io.Writer) io.WriteCloser {
+		return &otlpJSONWriter{ctx: ctx, out: w}
+	})
+	if err != nil {
+		return err
+	}
+	collectTraces(cmd, remotePort, tCh)
+	close(tCh)
+	return <-errCh
+}
+
+// otlpJSONWriter converts the binary trace data of one component, as returned by DumpTraces, into OTLP
+// JSON. The spans are decoded and written one ResourceSpans at a time, so the memory used is bounded by
+// the trace data of one component rather than by all data that is exported.
+type otlpJSONWriter struct {
+	ctx context.Context
+	out io.Writer
+}
+
+var newline = []byte{'\n'}
+
+func (w *otlpJSONWriter) Write(data []byte) (int, error) {
+	pr := tracing.NewProtoReader(bytes.NewReader(data), func() *tracepb.ResourceSpans { return &tracepb.ResourceSpans{} })
+	for {
+		rs, err := pr.ReadNext(w.ctx)
+		if err != nil {
+			if err == io.EOF {
+				return len(data), nil
+			}
+			return 0, err
+		}
+		js, err := protojson.Marshal(&tracepb.TracesData{ResourceSpans: []*tracepb.ResourceSpans{rs}})
+		if err != nil {
+			return 0, err
+		}
+		if _, err = w.out.Write(append(js, newline...)); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (w *otlpJSONWriter) Close() error {
+	return nil
+}
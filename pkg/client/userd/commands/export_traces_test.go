@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/tracing"
+)
+
+func resourceSpans(service string, spanNames ...string) *tracepb.ResourceSpans {
+	spans := make([]*tracepb.Span, len(spanNames))
+	for i, n := range spanNames {
+		spans[i] = &tracepb.Span{
+			TraceId: bytes.Repeat([]byte{byte(i + 1)}, 16),
+			SpanId:  bytes.Repeat([]byte{byte(i + 1)}, 8),
+			Name:    n,
+		}
+	}
+	return &tracepb.ResourceSpans{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{{
+			Key:   "service.name",
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: service}},
+		}}},
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: spans}},
+	}
+}
+
+func Test_otlpJSONWriter(t *testing.T) {
+	// Each chunk represents the trace data of one component
+	chunks := [][]*tracepb.ResourceSpans{
+		{resourceSpans("user-daemon", "Connect", "Status"), resourceSpans("user-daemon", "Quit")},
+		{resourceSpans("traffic-manager", "ArriveAsClient")},
+		{},
+	}
+
+	out := &bytes.Buffer{}
+	w := &otlpJSONWriter{ctx: context.Background(), out: out}
+	var expected []*tracepb.ResourceSpans
+	for _, chunk := range chunks {
+		buf := &bytes.Buffer{}
+		pw := tracing.NewProtoWriter(buf)
+		for _, rs := range chunk {
+			require.NoError(t, pw.Encode(rs))
+		}
+		n, err := w.Write(buf.Bytes())
+		require.NoError(t, err)
+		assert.Equal(t, buf.Len(), n)
+		expected = append(expected, chunk...)
+	}
+	require.NoError(t, w.Close())
+
+	sc := bufio.NewScanner(out)
+	i := 0
+	for ; sc.Scan(); i++ {
+		var td tracepb.TracesData
+		require.NoError(t, protojson.Unmarshal(sc.Bytes(), &td))
+		require.Len(t, td.ResourceSpans, 1)
+		require.Less(t, i, len(expected))
+		assert.True(t, proto.Equal(expected[i], td.ResourceSpans[0]), "line %d differs", i+1)
+	}
+	require.NoError(t, sc.Err())
+	assert.Equal(t, len(expected), i)
+}
+
+func Test_otlpJSONWriter_corrupt(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, tracing.NewProtoWriter(buf).Encode(resourceSpans("root-daemon", "Connect")))
+	w := &otlpJSONWriter{ctx: context.Background(), out: &bytes.Buffer{}}
+	_, err := w.Write(buf.Bytes()[:buf.Len()-3])
+	assert.Error(t, err)
+}
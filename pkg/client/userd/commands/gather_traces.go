@@ -4,6 +4,7 @@ import (
 	"compress/gzip"
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"path/filepath"
@@ -62,7 +63,10 @@ func tracesFor(ctx context.Context, conn *grpc.ClientConn, ch chan []byte) error
 	return nil
 }
 
-func launchTraceWriter(ctx context.Context, destFile string) (chan []byte, chan error, error) {
+// launchTraceWriter creates the given destFile and starts a goroutine that writes the trace data received
+// on the returned channel to that file using a writer created by newWriter. The writer is closed before
+// the file when the channel is closed.
+func launchTraceWriter(ctx context.Context, destFile string, newWriter func(io.Writer) io.WriteCloser) (chan []byte, chan error, error) {
 	ch := make(chan []byte)
 	if !filepath.IsAbs(destFile) {
 		wd := GetCwd(ctx)
@@ -75,9 +79,9 @@ func launchTraceWriter(ctx context.Context, destFile string) (chan []byte, chan
 	errCh := make(chan error)
 
 	go func() {
-		zipW := gzip.NewWriter(file)
+		tw := newWriter(file)
 		defer func() {
-			err = zipW.Close()
+			err = tw.Close()
 			if err != nil {
 				errCh <- err
 				return
@@ -98,7 +102,7 @@ func launchTraceWriter(ctx context.Context, destFile string) (chan []byte, chan
 				if !ok {
 					return
 				}
-				_, err := zipW.Write(data)
+				_, err := tw.Write(data)
 				if err != nil {
 					errCh <- err
 					return
@@ -206,13 +210,23 @@ func agentTraces(cmd *cobra.Command, tCh chan []byte, remotePort string) error {
 }
 
 func gatherTraces(cmd *cobra.Command, remotePort uint16, destFile string) error {
-	ctx := cmd.Context()
-	port := strconv.FormatUint(uint64(remotePort), 10)
-
-	tCh, errCh, err := launchTraceWriter(ctx, destFile)
+	tCh, errCh, err := launchTraceWriter(cmd.Context(), destFile, func(w io.Writer) io.WriteCloser {
+		return gzip.NewWriter(w)
+	})
 	if err != nil {
 		return err
 	}
+	collectTraces(cmd, remotePort, tCh)
+	close(tCh)
+	return <-errCh
+}
+
+// collectTraces sends the trace data of the root daemon, the user daemon, the traffic-manager, and all
+// traffic-agents to the given channel. Failures to collect data from a component are reported on the
+// command's stderr.
+func collectTraces(cmd *cobra.Command, remotePort uint16, tCh chan []byte) {
+	ctx := cmd.Context()
+	port := strconv.FormatUint(uint64(remotePort), 10)
 
 	wg := &sync.WaitGroup{}
 	wg.Add(4)
@@ -235,7 +249,7 @@ func gatherTraces(cmd *cobra.Command, remotePort uint16, destFile string) error
 
 	go func() {
 		defer wg.Done()
-		err = trafficManagerTraces(ctx, tCh, port)
+		err := trafficManagerTraces(ctx, tCh, port)
 		if err != nil {
 			fmt.Fprintf(cmd.ErrOrStderr(), "failed to collect traffic-manager traces: %v", err)
 		}
@@ -250,9 +264,4 @@ func gatherTraces(cmd *cobra.Command, remotePort uint16, destFile string) error
 	}()
 
 	wg.Wait()
-
-	close(tCh)
-	err = <-errCh
-
-	return err
 }
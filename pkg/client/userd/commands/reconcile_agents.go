@@ -0,0 +1,249 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentmap"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/trafficmgr"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/throttle"
+)
+
+// defaultAgentPort is the port that the traffic-manager assigns to the first intercepted port of an agent
+// unless it's been configured otherwise.
+const defaultAgentPort = 9900
+
+type reconcileOptions struct {
+	agentImage string
+	force      bool
+	throttle   throttle.Config
+}
+
+// AgentsCommand returns the "agents" command, which groups the commands that manage traffic-agents.
+func AgentsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "agents",
+		Args: cobra.NoArgs,
+
+		Short: "Manage traffic-agents",
+	}
+	cmd.AddCommand(ReconcileAgents())
+	return cmd
+}
+
+func ReconcileAgents() *cobra.Command {
+	ro := reconcileOptions{}
+	var rate string
+	cmd := &cobra.Command{
+		Use:  "reconcile",
+		Args: cobra.NoArgs,
+
+		Short: "Reconcile traffic-agents with the current configuration",
+		Long: "Reconcile the configuration of all installed traffic-agents with the current configuration.\n\n" +
+			"The desired configuration of each agent is generated from its workload and services, using the agent " +
+			"image given by --agent-image, or by the images.agentImage setting of the config.yml. Agents that differ " +
+			"from their desired configuration are re-injected, which triggers a rollout of their workload. Agents " +
+			"with active intercepts are skipped unless --force is used.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			var err error
+			if ro.throttle.Rate, err = throttle.ParseRate(rate); err != nil {
+				return errcat.User.New(err)
+			}
+			ctx := cmd.Context()
+			if ro.agentImage == "" {
+				if img := client.GetConfig(ctx).Images; img.AgentImage(ctx) != "" {
+					ro.agentImage = img.Registry(ctx) + "/" + img.AgentImage(ctx)
+				}
+			}
+			sess := trafficmgr.GetSession(ctx)
+			intercepted, err := interceptedAgents(ctx, sess.ManagerClient())
+			if err != nil {
+				return err
+			}
+			return reconcileAgents(ctx, sess.GetCurrentNamespaces(true), intercepted, &ro, cmd.OutOrStdout())
+		},
+		Annotations: map[string]string{
+			CommandRequiresSession: "true",
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&ro.agentImage, "agent-image", "",
+		`The fully qualified name of the desired traffic-agent image. Defaults to the image given by the config.yml. `+
+			`The image of an agent is not considered to be drifted when no image is given`)
+	flags.BoolVar(&ro.force, "force", false, "Reconcile agents even when they have active intercepts")
+	flags.IntVar(&ro.throttle.Concurrency, "install-concurrency", 1,
+		"Maximum number of agents that are re-injected concurrently. Zero means no limit")
+	flags.StringVar(&rate, "install-rate", "",
+		`Maximum rate at which agent re-injections are started, e.g. "2/s" or "10/m". Empty means no limit`)
+	return cmd
+}
+
+// interceptedAgents returns the set of agents, on the form <name>.<namespace>, that have intercepts.
+func interceptedAgents(ctx context.Context, mc manager.ManagerClient) (map[string]struct{}, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// An empty session makes the traffic-manager return the intercepts of all clients.
+	stream, err := mc.WatchIntercepts(ctx, &manager.SessionInfo{})
+	if err != nil {
+		return nil, fmt.Errorf("manager.WatchIntercepts dial: %w", err)
+	}
+	snapshot, err := stream.Recv()
+	if err != nil {
+		return nil, fmt.Errorf("manager.WatchIntercepts recv: %w", err)
+	}
+	intercepted := make(map[string]struct{}, len(snapshot.Intercepts))
+	for _, ii := range snapshot.Intercepts {
+		intercepted[ii.Spec.Agent+"."+ii.Spec.Namespace] = struct{}{}
+	}
+	return intercepted, nil
+}
+
+// driftedAgent is an agent whose config in the agents ConfigMap differs from its desired config.
+type driftedAgent struct {
+	name      string
+	namespace string
+	yml       string
+}
+
+// reconcileAgents finds the agents in the given namespaces whose configs differ from the desired configs, and
+// stores the desired configs in the agents ConfigMap of each namespace. The traffic-manager will then roll out
+// the affected workloads so that their agents are re-injected. Agents that are found in the intercepted set are
+// skipped unless the force option is set.
+func reconcileAgents(ctx context.Context, namespaces []string, intercepted map[string]struct{}, ro *reconcileOptions, out io.Writer) error {
+	var drifted []*driftedAgent
+	inSync := 0
+	for _, ns := range namespaces {
+		cm, err := k8sapi.GetK8sInterface(ctx).CoreV1().ConfigMaps(ns).Get(ctx, agentconfig.ConfigMap, meta.GetOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				// No agents in this namespace
+				continue
+			}
+			return fmt.Errorf("unable to get ConfigMap %s.%s: %w", agentconfig.ConfigMap, ns, err)
+		}
+		names := make([]string, 0, len(cm.Data))
+		for name := range cm.Data {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			ac := &agentconfig.Sidecar{}
+			if err = yaml.Unmarshal([]byte(cm.Data[name]), ac); err != nil {
+				fmt.Fprintf(out, "Unable to decode the config of agent %s.%s: %v\n", name, ns, err)
+				continue
+			}
+			if ac.Manual || ac.Create {
+				// Manually managed, or not yet generated by the traffic-manager
+				continue
+			}
+			wl, err := k8sapi.GetWorkload(ctx, ac.WorkloadName, ac.Namespace, ac.WorkloadKind)
+			if err != nil {
+				fmt.Fprintf(out, "Unable to get %s %s.%s: %v\n", ac.WorkloadKind, ac.WorkloadName, ac.Namespace, err)
+				continue
+			}
+			desired, err := agentmap.Generate(ctx, wl, generatorConfig(ac, ro.agentImage))
+			if err != nil {
+				fmt.Fprintf(out, "Unable to generate the desired config of agent %s.%s: %v\n", name, ns, err)
+				continue
+			}
+			currentYml, err := encodeSidecar(ac)
+			if err != nil {
+				return err
+			}
+			desiredYml, err := encodeSidecar(desired)
+			if err != nil {
+				return err
+			}
+			if currentYml == desiredYml {
+				inSync++
+				continue
+			}
+			if _, ok := intercepted[name+"."+ns]; ok && !ro.force {
+				fmt.Fprintf(out, "Skipping agent %s.%s because it has active intercepts. Use --force to reconcile it anyway\n", name, ns)
+				continue
+			}
+			drifted = append(drifted, &driftedAgent{name: name, namespace: ns, yml: desiredYml})
+		}
+	}
+
+	fmt.Fprintf(out, "Found %d drifted agents and %d agents that are up to date\n", len(drifted), inSync)
+	if len(drifted) == 0 {
+		return nil
+	}
+	return throttle.Run(ctx, ro.throttle, drifted, storeAgentConfig, func(done, total int, da *driftedAgent, err error) {
+		if err != nil {
+			fmt.Fprintf(out, "[%d/%d] failed to reconcile agent %s.%s: %v\n", done, total, da.name, da.namespace, err)
+		} else {
+			fmt.Fprintf(out, "[%d/%d] reconciled agent %s.%s\n", done, total, da.name, da.namespace)
+		}
+	})
+}
+
+// generatorConfig returns a config that will make agentmap.Generate produce a config that retains the
+// settings of the given agent config. The agent image is replaced unless agentImage is empty.
+func generatorConfig(ac *agentconfig.Sidecar, agentImage string) *agentmap.GeneratorConfig {
+	gc := &agentmap.GeneratorConfig{
+		APIPort:             ac.APIPort,
+		TracingPort:         ac.TracingPort,
+		QualifiedAgentImage: ac.AgentImage,
+		ManagerNamespace:    strings.TrimPrefix(ac.ManagerHost, agentmap.ManagerAppName+"."),
+		LogLevel:            ac.LogLevel,
+	}
+	if agentImage != "" {
+		gc.QualifiedAgentImage = agentImage
+	}
+	// The generator assigns consecutive agent ports starting with the configured one, so the lowest port in use
+	// is the configured one.
+	for _, cc := range ac.Containers {
+		for _, ic := range cc.Intercepts {
+			if gc.AgentPort == 0 || ic.AgentPort < gc.AgentPort {
+				gc.AgentPort = ic.AgentPort
+			}
+		}
+	}
+	if gc.AgentPort == 0 {
+		gc.AgentPort = defaultAgentPort
+	}
+	return gc
+}
+
+func encodeSidecar(ac *agentconfig.Sidecar) (string, error) {
+	bf := bytes.Buffer{}
+	if err := yaml.NewEncoder(&bf).Encode(ac); err != nil {
+		return "", err
+	}
+	return bf.String(), nil
+}
+
+// storeAgentConfig stores the config of the given agent in the agents ConfigMap of its namespace.
+func storeAgentConfig(ctx context.Context, da *driftedAgent) error {
+	api := k8sapi.GetK8sInterface(ctx).CoreV1().ConfigMaps(da.namespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := api.Get(ctx, agentconfig.ConfigMap, meta.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[da.name] = da.yml
+		_, err = api.Update(ctx, cm, meta.UpdateOptions{})
+		return err
+	})
+}
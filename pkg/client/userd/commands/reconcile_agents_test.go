@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentmap"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+const (
+	oldAgentImage = "docker.io/datawire/tel2:2.6.0"
+	newAgentImage = "docker.io/datawire/tel2:2.7.0"
+)
+
+func reconcileObjects(name string) []runtime.Object {
+	labels := map[string]string{"app": name}
+	return []runtime.Object{
+		&core.Service{
+			TypeMeta:   meta.TypeMeta{Kind: "Service", APIVersion: "v1"},
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "some-ns", UID: types.UID(name + "-uid")},
+			Spec: core.ServiceSpec{
+				Ports: []core.ServicePort{{
+					Name:       "http",
+					Protocol:   "TCP",
+					Port:       80,
+					TargetPort: intstr.FromString("http"),
+				}},
+				Selector: labels,
+			},
+		},
+		&apps.Deployment{
+			TypeMeta:   meta.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "some-ns"},
+			Spec: apps.DeploymentSpec{
+				Selector: &meta.LabelSelector{MatchLabels: labels},
+				Template: core.PodTemplateSpec{
+					ObjectMeta: meta.ObjectMeta{Labels: labels},
+					Spec: core.PodSpec{Containers: []core.Container{{
+						Name:  name,
+						Image: "example/" + name,
+						Ports: []core.ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: "TCP"}},
+					}}},
+				},
+			},
+		},
+	}
+}
+
+func Test_reconcileAgents(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	names := []string{"busy", "echo", "fine"}
+	var objs []runtime.Object
+	for _, name := range names {
+		objs = append(objs, reconcileObjects(name)...)
+	}
+	clientset := fake.NewSimpleClientset(objs...)
+	ctx = k8sapi.WithK8sInterface(ctx, clientset)
+
+	// "fine" is already using the new image. The others have drifted.
+	data := make(map[string]string, len(names))
+	for _, name := range names {
+		img := oldAgentImage
+		if name == "fine" {
+			img = newAgentImage
+		}
+		wl, err := k8sapi.GetWorkload(ctx, name, "some-ns", "Deployment")
+		require.NoError(t, err)
+		ac, err := agentmap.Generate(ctx, wl, &agentmap.GeneratorConfig{
+			AgentPort:           9900,
+			TracingPort:         15766,
+			QualifiedAgentImage: img,
+			ManagerNamespace:    "ambassador",
+			LogLevel:            "info",
+		})
+		require.NoError(t, err)
+		data[name], err = encodeSidecar(ac)
+		require.NoError(t, err)
+	}
+	_, err := clientset.CoreV1().ConfigMaps("some-ns").Create(ctx, &core.ConfigMap{
+		ObjectMeta: meta.ObjectMeta{Name: agentconfig.ConfigMap, Namespace: "some-ns"},
+		Data:       data,
+	}, meta.CreateOptions{})
+	require.NoError(t, err)
+
+	agentImage := func(name string) string {
+		cm, err := clientset.CoreV1().ConfigMaps("some-ns").Get(ctx, agentconfig.ConfigMap, meta.GetOptions{})
+		require.NoError(t, err)
+		ac := agentconfig.Sidecar{}
+		require.NoError(t, yaml.Unmarshal([]byte(cm.Data[name]), &ac))
+		assert.Equal(t, uint16(9900), ac.Containers[0].Intercepts[0].AgentPort)
+		assert.Equal(t, "traffic-manager.ambassador", ac.ManagerHost)
+		return ac.AgentImage
+	}
+
+	intercepted := map[string]struct{}{"busy.some-ns": {}}
+	ro := &reconcileOptions{agentImage: newAgentImage}
+
+	t.Run("skips intercepted", func(t *testing.T) {
+		out := &strings.Builder{}
+		require.NoError(t, reconcileAgents(ctx, []string{"some-ns", "other-ns"}, intercepted, ro, out))
+		assert.Contains(t, out.String(), "Skipping agent busy.some-ns because it has active intercepts")
+		assert.Contains(t, out.String(), "Found 1 drifted agents and 1 agents that are up to date")
+		assert.Contains(t, out.String(), "[1/1] reconciled agent echo.some-ns")
+		assert.Equal(t, newAgentImage, agentImage("echo"))
+		assert.Equal(t, oldAgentImage, agentImage("busy"))
+		assert.Equal(t, newAgentImage, agentImage("fine"))
+	})
+
+	t.Run("force", func(t *testing.T) {
+		ro.force = true
+		out := &strings.Builder{}
+		require.NoError(t, reconcileAgents(ctx, []string{"some-ns"}, intercepted, ro, out))
+		assert.Contains(t, out.String(), "Found 1 drifted agents and 2 agents that are up to date")
+		assert.Contains(t, out.String(), "[1/1] reconciled agent busy.some-ns")
+		assert.Equal(t, newAgentImage, agentImage("busy"))
+	})
+
+	t.Run("in sync", func(t *testing.T) {
+		out := &strings.Builder{}
+		require.NoError(t, reconcileAgents(ctx, []string{"some-ns"}, nil, ro, out))
+		assert.Contains(t, out.String(), "Found 0 drifted agents and 3 agents that are up to date")
+	})
+}
@@ -130,9 +130,12 @@ func (s *Service) Status(c context.Context, _ *empty.Empty) (result *rpc.Connect
 	s.logCall(c, "Status", func(c context.Context) {
 		s.sessionLock.RLock()
 		defer s.sessionLock.RUnlock()
-		if s.session == nil {
+		switch {
+		case s.reconnecting:
+			result = &rpc.ConnectInfo{Error: rpc.ConnectInfo_RECONNECTING, ErrorText: s.reconnectError}
+		case s.session == nil:
 			result = &rpc.ConnectInfo{Error: rpc.ConnectInfo_DISCONNECTED}
-		} else {
+		default:
 			result = s.session.Status(s.sessionContext)
 		}
 	})
@@ -308,6 +311,13 @@ func (s *Service) RemoveInterceptor(ctx context.Context, interceptor *rpc.Interc
 	})
 }
 
+func (s *Service) Health(c context.Context, _ *empty.Empty) (result *rpc.HealthReport, err error) {
+	s.logCall(c, "Health", func(c context.Context) {
+		result = s.health(c)
+	})
+	return
+}
+
 func (s *Service) List(c context.Context, lr *rpc.ListRequest) (result *rpc.WorkloadInfoSnapshot, err error) {
 	err = s.withSession(c, "List", func(c context.Context, session trafficmgr.Session) error {
 		result, err = session.WorkloadInfoSnapshot(c, []string{lr.Namespace}, lr.Filter, true)
@@ -0,0 +1,83 @@
+package userd
+
+import (
+	"context"
+	"strings"
+
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+// health reports the readiness of the user daemon, the cluster connection, the root daemon, the outbound
+// proxy, and each intercept of the current session. The outbound proxy is reported regardless of whether
+// there are any intercepts.
+func (s *Service) health(c context.Context) *rpc.HealthReport {
+	r := &rpc.HealthReport{Ready: true}
+	add := func(name string, ready bool, reason string) {
+		r.Components = append(r.Components, &rpc.ComponentHealth{Name: name, Ready: ready, Reason: reason})
+		r.Ready = r.Ready && ready
+	}
+	add("user-daemon", true, "")
+
+	s.sessionLock.RLock()
+	session, sessionContext := s.session, s.sessionContext
+	reconnecting, reconnectError := s.reconnecting, s.reconnectError
+	s.sessionLock.RUnlock()
+
+	var ci *rpc.ConnectInfo
+	switch {
+	case reconnecting:
+		reason := "reconnecting"
+		if reconnectError != "" {
+			reason += ": " + reconnectError
+		}
+		add("cluster", false, reason)
+	case session == nil:
+		add("cluster", false, "not connected")
+	default:
+		ci = session.Status(sessionContext)
+		add("cluster", true, "")
+	}
+
+	var ds *daemon.DaemonStatus
+	if s.daemonClient == nil {
+		add("root-daemon", false, "not connected")
+	} else {
+		var err error
+		if ds, err = s.daemonClient.Status(c, &empty.Empty{}); err != nil {
+			add("root-daemon", false, err.Error())
+		} else {
+			add("root-daemon", true, "")
+		}
+	}
+
+	switch oc := ds.GetOutboundConfig(); {
+	case ds == nil:
+		add("outbound", false, "the root daemon is not available")
+	case oc == nil:
+		add("outbound", false, "the root daemon has no session")
+	case ci != nil && oc.Session.GetSessionId() != ci.SessionInfo.GetSessionId():
+		add("outbound", false, "the root daemon serves another session")
+	case !ds.RoutingReady:
+		add("outbound", false, "the network is not yet routed to the cluster")
+	default:
+		add("outbound", true, "")
+	}
+
+	for _, ii := range ci.GetIntercepts().GetIntercepts() {
+		name := "intercept/" + ii.Spec.Name
+		if ii.Disposition == manager.InterceptDispositionType_ACTIVE {
+			add(name, true, "")
+			continue
+		}
+		reason := strings.ToLower(ii.Disposition.String())
+		if ii.Message != "" {
+			reason += ": " + ii.Message
+		}
+		add(name, false, reason)
+	}
+	return r
+}
@@ -0,0 +1,92 @@
+package userd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/daemon"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+// statusDaemonClient is a daemon.DaemonClient that only implements Status.
+type statusDaemonClient struct {
+	daemon.DaemonClient
+	status *daemon.DaemonStatus
+}
+
+func (c *statusDaemonClient) Status(context.Context, *empty.Empty, ...grpc.CallOption) (*daemon.DaemonStatus, error) {
+	return c.status, nil
+}
+
+func healthComponents(r *rpc.HealthReport) map[string]*rpc.ComponentHealth {
+	m := make(map[string]*rpc.ComponentHealth, len(r.Components))
+	for _, c := range r.Components {
+		m[c.Name] = c
+	}
+	return m
+}
+
+func TestService_health(t *testing.T) {
+	t.Run("not connected", func(t *testing.T) {
+		s := &Service{}
+		r := s.health(context.Background())
+		assert.False(t, r.Ready)
+		cs := healthComponents(r)
+		assert.True(t, cs["user-daemon"].Ready)
+		assert.Equal(t, "not connected", cs["cluster"].Reason)
+		assert.Equal(t, "not connected", cs["root-daemon"].Reason)
+		assert.False(t, cs["outbound"].Ready)
+	})
+
+	ctx, s := newReconnectTestService(t)
+	defer s.cancelSession()
+	sessionID := s.session.Status(s.sessionContext).SessionInfo.SessionId
+
+	t.Run("outbound not routed", func(t *testing.T) {
+		s.daemonClient = &statusDaemonClient{status: &daemon.DaemonStatus{
+			OutboundConfig: &daemon.OutboundInfo{Session: &manager.SessionInfo{SessionId: sessionID}},
+		}}
+		r := s.health(ctx)
+		assert.False(t, r.Ready)
+		cs := healthComponents(r)
+		assert.True(t, cs["cluster"].Ready)
+		assert.True(t, cs["root-daemon"].Ready)
+		assert.Equal(t, "the network is not yet routed to the cluster", cs["outbound"].Reason)
+	})
+
+	t.Run("router running without routes", func(t *testing.T) {
+		s.daemonClient = &statusDaemonClient{status: &daemon.DaemonStatus{
+			OutboundConfig: &daemon.OutboundInfo{Session: &manager.SessionInfo{SessionId: sessionID}},
+			RouterRunning:  true,
+		}}
+		r := s.health(ctx)
+		assert.False(t, r.Ready)
+		assert.Equal(t, "the network is not yet routed to the cluster", healthComponents(r)["outbound"].Reason)
+	})
+
+	t.Run("other session", func(t *testing.T) {
+		s.daemonClient = &statusDaemonClient{status: &daemon.DaemonStatus{
+			OutboundConfig: &daemon.OutboundInfo{Session: &manager.SessionInfo{SessionId: "other"}},
+			RoutingReady:   true,
+		}}
+		r := s.health(ctx)
+		assert.False(t, r.Ready)
+		assert.Equal(t, "the root daemon serves another session", healthComponents(r)["outbound"].Reason)
+	})
+
+	t.Run("ready without intercepts", func(t *testing.T) {
+		s.daemonClient = &statusDaemonClient{status: &daemon.DaemonStatus{
+			OutboundConfig: &daemon.OutboundInfo{Session: &manager.SessionInfo{SessionId: sessionID}},
+			RoutingReady:   true,
+		}}
+		r := s.health(ctx)
+		require.True(t, r.Ready, r.Components)
+		assert.Len(t, r.Components, 4)
+	})
+}
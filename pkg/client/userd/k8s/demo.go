@@ -0,0 +1,346 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	authz "k8s.io/api/authorization/v1"
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+)
+
+// DemoContext is the name of the kubeconfig context reported by a demo cluster.
+const DemoContext = "demo"
+
+// demoResources are the resources that the demo API server knows about, and the kind of their objects.
+var demoResources = map[string]schema.GroupVersionKind{
+	"namespaces":   core.SchemeGroupVersion.WithKind("Namespace"),
+	"services":     core.SchemeGroupVersion.WithKind("Service"),
+	"pods":         core.SchemeGroupVersion.WithKind("Pod"),
+	"deployments":  apps.SchemeGroupVersion.WithKind("Deployment"),
+	"replicasets":  apps.SchemeGroupVersion.WithKind("ReplicaSet"),
+	"statefulsets": apps.SchemeGroupVersion.WithKind("StatefulSet"),
+}
+
+// NewDemoCluster returns a Cluster that is backed by an in-process fake API server instead of a real cluster.
+// The fake API server serves a couple of sample workloads in the "default" namespace, and it runs until the
+// given context is cancelled.
+func NewDemoCluster(c context.Context, namespaces []string) (*Cluster, error) {
+	store, err := newDemoStore(demoObjects())
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	srv := &http.Server{
+		Handler: &demoAPIServer{
+			store: store,
+			codec: scheme.Codecs.LegacyCodec(core.SchemeGroupVersion, apps.SchemeGroupVersion, authz.SchemeGroupVersion),
+		},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.Serve(l); err != http.ErrServerClosed {
+			dlog.Errorf(c, "demo API server failed: %v", err)
+		}
+	}()
+
+	server := "http://" + l.Addr().String()
+	config := &Config{
+		Namespace:  "default",
+		Context:    DemoContext,
+		Server:     server,
+		RestConfig: &rest.Config{Host: server},
+		kubeconfigExtension: kubeconfigExtension{
+			Manager: &managerConfig{
+				Namespace: client.GetEnv(c).ManagerNamespace,
+			},
+		},
+	}
+	cluster, err := NewCluster(c, config, namespaces)
+	if err != nil {
+		_ = srv.Close()
+		return nil, err
+	}
+	go func() {
+		<-c.Done()
+		_ = srv.Close()
+	}()
+	return cluster, nil
+}
+
+// demoStore holds the objects of the demo cluster, keyed by their resource. The objects never change, because
+// nothing in a demo session modifies the cluster.
+type demoStore map[schema.GroupVersionResource][]runtime.Object
+
+func newDemoStore(objs []runtime.Object) (demoStore, error) {
+	resources := make(map[schema.GroupVersionKind]schema.GroupVersionResource, len(demoResources))
+	for resource, gvk := range demoResources {
+		resources[gvk] = gvk.GroupVersion().WithResource(resource)
+	}
+	store := make(demoStore)
+	for _, obj := range objs {
+		gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+		if err != nil {
+			return nil, err
+		}
+		gvr, ok := resources[gvks[0]]
+		if !ok {
+			return nil, fmt.Errorf("%s is not a demo resource", gvks[0])
+		}
+		store[gvr] = append(store[gvr], obj)
+	}
+	return store, nil
+}
+
+// get returns a copy of the object with the given namespace and name.
+func (s demoStore) get(gvr schema.GroupVersionResource, namespace, name string) (runtime.Object, error) {
+	for _, obj := range s[gvr] {
+		m, err := apimeta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		if m.GetNamespace() == namespace && m.GetName() == name {
+			return obj.DeepCopyObject(), nil
+		}
+	}
+	return nil, apierrors.NewNotFound(gvr.GroupResource(), name)
+}
+
+// list returns a list of the given kind with copies of the objects in the given namespace, or in all
+// namespaces when the namespace is empty.
+func (s demoStore) list(gvr schema.GroupVersionResource, gvk schema.GroupVersionKind, namespace string) (runtime.Object, error) {
+	list, err := scheme.Scheme.New(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	if err != nil {
+		return nil, err
+	}
+	var items []runtime.Object
+	for _, obj := range s[gvr] {
+		m, err := apimeta.Accessor(obj)
+		if err != nil {
+			return nil, err
+		}
+		if namespace == "" || m.GetNamespace() == namespace {
+			items = append(items, obj.DeepCopyObject())
+		}
+	}
+	if err = apimeta.SetList(list, items); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// demoAPIServer is a stand-in for the Kubernetes API server that serves the objects of a demoStore. It
+// implements just enough of the API for a session to run, i.e. the server version, get, list, and watch of
+// the resources in demoResources, and self subject access reviews, which are always allowed.
+type demoAPIServer struct {
+	store demoStore
+	codec runtime.Codec
+}
+
+func (s *demoAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/version" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&version.Info{Major: "1", Minor: "24", GitVersion: "v1.24.2"})
+		return
+	}
+
+	gv, namespace, resource, name, ok := parseAPIPath(r.URL.Path)
+	if !ok {
+		s.writeError(w, apierrors.NewNotFound(schema.GroupResource{}, r.URL.Path))
+		return
+	}
+	gr := gv.WithResource(resource).GroupResource()
+	if gv == authz.SchemeGroupVersion && resource == "selfsubjectaccessreviews" && r.Method == http.MethodPost {
+		s.allowAccess(w, r)
+		return
+	}
+	gvk, ok := demoResources[resource]
+	if !ok || gvk.GroupVersion() != gv {
+		s.writeError(w, apierrors.NewNotFound(gr, name))
+		return
+	}
+	if r.Method != http.MethodGet {
+		s.writeError(w, apierrors.NewMethodNotSupported(gr, strings.ToLower(r.Method)))
+		return
+	}
+
+	gvr := gv.WithResource(resource)
+	var obj runtime.Object
+	var err error
+	switch {
+	case name != "":
+		obj, err = s.store.get(gvr, namespace, name)
+	case r.URL.Query().Get("watch") == "true":
+		s.watch(w, r)
+		return
+	default:
+		obj, err = s.store.list(gvr, gvk, namespace)
+	}
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	s.writeObject(w, http.StatusOK, obj)
+}
+
+// parseAPIPath parses paths on the form /api/<version>[/namespaces/<namespace>]/<resource>[/<name>] and
+// /apis/<group>/<version>[/namespaces/<namespace>]/<resource>[/<name>].
+func parseAPIPath(path string) (gv schema.GroupVersion, namespace, resource, name string, ok bool) {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(segs) > 2 && segs[0] == "api":
+		gv = schema.GroupVersion{Version: segs[1]}
+		segs = segs[2:]
+	case len(segs) > 3 && segs[0] == "apis":
+		gv = schema.GroupVersion{Group: segs[1], Version: segs[2]}
+		segs = segs[3:]
+	default:
+		return gv, "", "", "", false
+	}
+	if len(segs) > 2 && segs[0] == "namespaces" {
+		namespace = segs[1]
+		segs = segs[2:]
+	}
+	switch len(segs) {
+	case 1:
+		resource = segs[0]
+	case 2:
+		resource = segs[0]
+		name = segs[1]
+	default:
+		return gv, "", "", "", false
+	}
+	return gv, namespace, resource, name, true
+}
+
+// watch responds to a watch request. The objects of the demo cluster never change, so no events are sent. The
+// response remains open until the client ends the request.
+func (s *demoAPIServer) watch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	<-r.Context().Done()
+}
+
+func (s *demoAPIServer) allowAccess(w http.ResponseWriter, r *http.Request) {
+	ar := &authz.SelfSubjectAccessReview{}
+	data, err := io.ReadAll(r.Body)
+	if err == nil {
+		_, _, err = s.codec.Decode(data, nil, ar)
+	}
+	if err != nil {
+		s.writeError(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	ar.Status.Allowed = true
+	s.writeObject(w, http.StatusCreated, ar)
+}
+
+func (s *demoAPIServer) writeObject(w http.ResponseWriter, code int, obj runtime.Object) {
+	data, err := runtime.Encode(s.codec, obj)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(data)
+}
+
+func (s *demoAPIServer) writeError(w http.ResponseWriter, err error) {
+	var st meta.Status
+	if se, ok := err.(apierrors.APIStatus); ok {
+		st = se.Status()
+	} else {
+		st = apierrors.NewInternalError(err).ErrStatus
+	}
+	st.Kind = "Status"
+	st.APIVersion = "v1"
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(st.Code))
+	_ = json.NewEncoder(w).Encode(&st)
+}
+
+// demoObjects returns the namespaces and the sample workloads, with their services, of the demo cluster.
+func demoObjects() []runtime.Object {
+	objs := []runtime.Object{
+		&core.Namespace{ObjectMeta: meta.ObjectMeta{Name: "default", UID: "b5d5b1c4-7f4e-4f3a-9c8a-2f0d8e3c1d01"}},
+		&core.Namespace{ObjectMeta: meta.ObjectMeta{Name: "kube-system", UID: "b5d5b1c4-7f4e-4f3a-9c8a-2f0d8e3c1d02"}},
+	}
+	objs = append(objs, demoWorkload("echo-easy", "ghcr.io/telepresenceio/echo-server:latest", 8080, "03")...)
+	objs = append(objs, demoWorkload("hello", "docker.io/datawire/hello-world:latest", 8000, "05")...)
+	return objs
+}
+
+// demoWorkload returns a Deployment and a Service that exposes the Deployment's single container port on
+// port 80 of the service.
+func demoWorkload(name, image string, port int32, uidSuffix string) []runtime.Object {
+	labels := map[string]string{"app": name}
+	replicas := int32(1)
+	dep := &apps.Deployment{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID("c8a1e0f2-3b7d-4d6e-8f9a-1e2d3c4b5a" + uidSuffix),
+			Labels:    labels,
+		},
+		Spec: apps.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &meta.LabelSelector{MatchLabels: labels},
+			Template: core.PodTemplateSpec{
+				ObjectMeta: meta.ObjectMeta{Labels: labels},
+				Spec: core.PodSpec{
+					Containers: []core.Container{{
+						Name:  name,
+						Image: image,
+						Ports: []core.ContainerPort{{Name: "http", ContainerPort: port, Protocol: core.ProtocolTCP}},
+					}},
+				},
+			},
+		},
+		Status: apps.DeploymentStatus{Replicas: replicas, ReadyReplicas: replicas, AvailableReplicas: replicas},
+	}
+	svc := &core.Service{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID("d9b2f1a3-4c8e-4e7f-9a0b-2f3e4d5c6b" + uidSuffix),
+		},
+		Spec: core.ServiceSpec{
+			Type:     core.ServiceTypeClusterIP,
+			Selector: labels,
+			Ports: []core.ServicePort{{
+				Name:       "http",
+				Port:       80,
+				Protocol:   core.ProtocolTCP,
+				TargetPort: intstr.FromString("http"),
+			}},
+		},
+	}
+	return []runtime.Object{dep, svc}
+}
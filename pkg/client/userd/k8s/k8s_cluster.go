@@ -30,6 +30,9 @@ type Cluster struct {
 	// Main
 	ki kubernetes.Interface
 
+	// tokenRefresher is non-nil when the cluster's bearer token is obtained from a credential plugin and expires.
+	tokenRefresher *TokenRefresher
+
 	// Current Namespace snapshot, get set by namespace Watcher.
 	// The boolean value indicates if this client is allowed to
 	// watch services and retrieve workloads in the namespace
@@ -118,10 +121,10 @@ func (kc *Cluster) namespaceAccessible(namespace string) (exists bool) {
 }
 
 func NewCluster(c context.Context, kubeFlags *Config, namespaces []string) (*Cluster, error) {
-	rs, err := kubeFlags.ConfigFlags.ToRESTConfig()
-	if err != nil {
-		return nil, err
-	}
+	// The RestConfig is shared with port-forward dialers, so the token refresher must be installed in it
+	// before it's used.
+	rs := kubeFlags.RestConfig
+	tr := NewTokenRefresher(c, rs)
 	cs, err := kubernetes.NewForConfig(rs)
 	if err != nil {
 		return nil, err
@@ -138,6 +141,7 @@ func NewCluster(c context.Context, kubeFlags *Config, namespaces []string) (*Clu
 		Config:           kubeFlags,
 		mappedNamespaces: namespaces,
 		ki:               cs,
+		tokenRefresher:   tr,
 	}
 
 	timedC, cancel := client.GetConfig(c).Timeouts.TimeoutContext(c, client.TimeoutClusterConnect)
@@ -181,6 +185,12 @@ func (kc *Cluster) GetClusterId(ctx context.Context) string {
 	return clusterID
 }
 
+// TokenRefresher returns the refresher of the cluster's bearer token, or nil if the token isn't refreshed
+// by Telepresence.
+func (kc *Cluster) TokenRefresher() *TokenRefresher {
+	return kc.tokenRefresher
+}
+
 func (kc *Cluster) WithK8sInterface(c context.Context) context.Context {
 	return k8sapi.WithK8sInterface(c, kc.ki)
 }
@@ -39,6 +39,14 @@ type dnsConfig struct {
 
 	// The maximum time to wait for a cluster side host lookup.
 	LookupTimeout metav1.Duration `json:"lookup-timeout,omitempty"`
+
+	// Search are domains that are added to the DNS search path. Names in these domains
+	// are always resolved by the cluster DNS.
+	Search []string `json:"search,omitempty"`
+
+	// Resolvers maps domains to the <ip>[:<port>] of a DNS server that names in that
+	// domain are sent to. The cluster DNS is used when such a server fails.
+	Resolvers map[string]string `json:"resolvers,omitempty"`
 }
 
 // The managerConfig is part of the kubeconfigExtension struct. It configures discovery of the traffic manager
@@ -80,8 +88,14 @@ func NewConfig(c context.Context, flagMap map[string]string) (*Config, error) {
 	// The KUBECONFIG entry is a copy of the KUBECONFIG environment variable sent to us from the CLI to give
 	// this long-running daemon a chance to update it. Using the --kubeconfig flag to send the info isn't
 	// sufficient because that flag doesn't allow for multiple path entries like the KUBECONFIG does.
-	if kcEnv, ok := flagMap["KUBECONFIG"]; ok {
-		delete(flagMap, "KUBECONFIG")
+	kcEnv, ok := flagMap["KUBECONFIG"]
+	delete(flagMap, "KUBECONFIG")
+	if kcFlag, hasFlag := flagMap["kubeconfig"]; hasFlag && kcFlag != "" {
+		// The --kubeconfig flag overrides the environment. Processes started by this daemon, such as
+		// credential plugins, must see the same config.
+		kcEnv, ok = kcFlag, true
+	}
+	if ok {
 		if err := os.Setenv("KUBECONFIG", kcEnv); err != nil {
 			return nil, err
 		}
@@ -104,7 +118,9 @@ func NewConfig(c context.Context, flagMap map[string]string) (*Config, error) {
 	configLoader := configFlags.ToRawKubeConfigLoader()
 	config, err := configLoader.RawConfig()
 	if err != nil {
-		return nil, err
+		// A file given using --kubeconfig that can't be loaded is a configuration error, just like a
+		// KUBECONFIG that doesn't define any context.
+		return nil, errcat.Config.Newf("unable to load kubeconfig: %w", err)
 	}
 
 	if len(config.Contexts) == 0 {
@@ -133,7 +149,12 @@ func NewConfig(c context.Context, flagMap map[string]string) (*Config, error) {
 
 	namespace := ctx.Namespace
 	if namespace == "" {
-		namespace = "default"
+		if cfg := client.GetConfig(c); cfg != nil {
+			namespace = cfg.Cluster.DefaultNamespace
+		}
+		if namespace == "" {
+			namespace = "default"
+		}
 	}
 
 	k := &Config{
@@ -0,0 +1,101 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: cluster
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: %s
+  context:
+    cluster: cluster
+    user: user
+users:
+- name: user
+  user:
+    token: token
+current-context: %s
+`
+
+func newConfigTestContext(t *testing.T) context.Context {
+	ctx := dlog.NewTestContext(t, false)
+	env, err := client.LoadEnv(ctx)
+	require.NoError(t, err)
+	return client.WithEnv(ctx, env)
+}
+
+func writeKubeconfig(t *testing.T, contextName string) string {
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	content := []byte(fmt.Sprintf(testKubeconfig, contextName, contextName))
+	require.NoError(t, os.WriteFile(kubeconfig, content, 0o600))
+	return kubeconfig
+}
+
+func TestNewConfig_kubeconfigFlag(t *testing.T) {
+	ctx := newConfigTestContext(t)
+	envConfig := writeKubeconfig(t, "from-env")
+	flagConfig := writeKubeconfig(t, "from-flag")
+	t.Setenv("KUBECONFIG", envConfig)
+
+	t.Run("overrides KUBECONFIG", func(t *testing.T) {
+		cfg, err := NewConfig(ctx, map[string]string{"KUBECONFIG": envConfig, "kubeconfig": flagConfig})
+		require.NoError(t, err)
+		assert.Equal(t, "from-flag", cfg.Context)
+		assert.Equal(t, flagConfig, os.Getenv("KUBECONFIG"))
+	})
+
+	t.Run("KUBECONFIG without flag", func(t *testing.T) {
+		cfg, err := NewConfig(ctx, map[string]string{"KUBECONFIG": envConfig})
+		require.NoError(t, err)
+		assert.Equal(t, "from-env", cfg.Context)
+		assert.Equal(t, envConfig, os.Getenv("KUBECONFIG"))
+	})
+
+	t.Run("no context definition", func(t *testing.T) {
+		empty := filepath.Join(t.TempDir(), "empty")
+		require.NoError(t, os.WriteFile(empty, nil, 0o600))
+		_, err := NewConfig(ctx, map[string]string{"kubeconfig": empty})
+		require.Error(t, err)
+		assert.Equal(t, errcat.Config, errcat.GetCategory(err))
+		assert.Contains(t, err.Error(), "kubeconfig has no context definition")
+	})
+
+	t.Run("nonexistent file", func(t *testing.T) {
+		_, err := NewConfig(ctx, map[string]string{"kubeconfig": filepath.Join(t.TempDir(), "nope")})
+		require.Error(t, err)
+		assert.Equal(t, errcat.Config, errcat.GetCategory(err))
+		assert.Contains(t, err.Error(), "unable to load kubeconfig")
+	})
+}
+
+func TestNewConfig_defaultNamespace(t *testing.T) {
+	ctx := newConfigTestContext(t)
+	kubeconfig := writeKubeconfig(t, "ctx")
+
+	cfg, err := NewConfig(ctx, map[string]string{"kubeconfig": kubeconfig})
+	require.NoError(t, err)
+	assert.Equal(t, "default", cfg.Namespace)
+
+	clientConfig := client.GetDefaultConfig()
+	clientConfig.Cluster.DefaultNamespace = "from-config"
+	ctx = client.WithConfig(ctx, &clientConfig)
+	cfg, err = NewConfig(ctx, map[string]string{"kubeconfig": kubeconfig})
+	require.NoError(t, err)
+	assert.Equal(t, "from-config", cfg.Namespace)
+}
@@ -0,0 +1,261 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/proc"
+)
+
+const (
+	// maxRefreshMargin is the longest time ahead of a token's expiry that a refresh is attempted.
+	maxRefreshMargin = 5 * time.Minute
+
+	// maxRetryInterval is the longest time between two attempts to refresh a token when the refresh fails.
+	maxRetryInterval = 30 * time.Second
+)
+
+// minRefreshInterval is the shortest time between two attempts to refresh a token. It prevents a tight loop
+// when a token is already expired, or expires too soon to be refreshed ahead of its expiry.
+var minRefreshInterval = 5 * time.Second
+
+// errClientCertificate is returned by execCredential when the credential plugin produces a client certificate,
+// which the TokenRefresher cannot provide.
+var errClientCertificate = errors.New("credential plugin produced a client certificate")
+
+// Token is a bearer token and the time when it expires.
+type Token struct {
+	Value  string
+	Expiry time.Time
+}
+
+// TokenRefresher keeps the bearer token obtained from a credential plugin fresh. The token is refreshed ahead
+// of its expiry, so that new connections to the API server don't fail because the token has expired, and a
+// warning is logged when a refresh fails.
+type TokenRefresher struct {
+	fetch func(context.Context) (*Token, error)
+
+	sync.RWMutex
+	current   *Token
+	obtained  time.Time
+	lastError error
+}
+
+// NewTokenRefresher returns a TokenRefresher for the given config, provided that the config uses a credential
+// plugin that produces a bearer token with an expiry. The config is modified so that the refresher provides
+// the token instead of the credential plugin. A nil refresher is returned when the config uses some other kind
+// of credentials, in which case the config is left untouched and client-go's own transport, if any, keeps the
+// credentials fresh. This is the case for a legacy auth-provider, such as oidc, that refreshes its token when
+// it expires, and for a credential plugin that produces a client certificate.
+func NewTokenRefresher(ctx context.Context, rc *rest.Config) *TokenRefresher {
+	if ap := rc.AuthProvider; ap != nil {
+		dlog.Debugf(ctx, "Tokens from auth-provider %s are refreshed by client-go", ap.Name)
+		return nil
+	}
+	ec := rc.ExecProvider
+	if ec == nil || ec.InteractiveMode == api.AlwaysExecInteractiveMode {
+		return nil
+	}
+	r := newTokenRefresher(func(ctx context.Context) (*Token, error) {
+		return execCredential(ctx, ec)
+	})
+	tok, err := r.fetch(ctx)
+	if err != nil {
+		if errors.Is(err, errClientCertificate) {
+			dlog.Debugf(ctx, "Credentials from credential plugin %s are refreshed by client-go: %v", ec.Command, err)
+		} else {
+			dlog.Warnf(ctx, "unable to obtain token from credential plugin %s, leaving refresh to client-go: %v", ec.Command, err)
+		}
+		return nil
+	}
+	if tok.Expiry.IsZero() {
+		// Token never expires.
+		return nil
+	}
+	r.set(tok)
+	rc.ExecProvider = nil
+	rc.Wrap(r.WrapTransport)
+	dlog.Infof(ctx, "Token from credential plugin %s expires at %s", ec.Command, tok.Expiry.Format(time.RFC3339))
+	return r
+}
+
+func newTokenRefresher(fetch func(context.Context) (*Token, error)) *TokenRefresher {
+	return &TokenRefresher{fetch: fetch}
+}
+
+// Expiry returns the expiry time of the current token.
+func (r *TokenRefresher) Expiry() time.Time {
+	r.RLock()
+	defer r.RUnlock()
+	if r.current == nil {
+		return time.Time{}
+	}
+	return r.current.Expiry
+}
+
+// LastError returns the error of the last attempt to refresh the token, or nil if that attempt succeeded.
+func (r *TokenRefresher) LastError() error {
+	r.RLock()
+	defer r.RUnlock()
+	return r.lastError
+}
+
+func (r *TokenRefresher) set(tok *Token) {
+	r.Lock()
+	r.current = tok
+	r.obtained = time.Now()
+	r.lastError = nil
+	r.Unlock()
+}
+
+func (r *TokenRefresher) setError(err error) {
+	r.Lock()
+	r.lastError = err
+	r.Unlock()
+}
+
+// refreshMargin returns how long before the expiry of the current token that it should be refreshed. The margin
+// is a fifth of the token's lifetime, but never more than maxRefreshMargin nor less than minRefreshInterval.
+func (r *TokenRefresher) refreshMargin() time.Duration {
+	margin := r.current.Expiry.Sub(r.obtained) / 5
+	if margin > maxRefreshMargin {
+		margin = maxRefreshMargin
+	}
+	if margin < minRefreshInterval {
+		margin = minRefreshInterval
+	}
+	return margin
+}
+
+// nextAttempt returns the time when the next attempt to refresh the token should be made. It is never earlier
+// than minRefreshInterval from now.
+func (r *TokenRefresher) nextAttempt() time.Time {
+	r.RLock()
+	defer r.RUnlock()
+	margin := r.refreshMargin()
+	var next time.Time
+	if r.lastError == nil {
+		next = r.current.Expiry.Add(-margin)
+	} else {
+		retry := margin / 4
+		if retry > maxRetryInterval {
+			retry = maxRetryInterval
+		}
+		next = time.Now().Add(retry)
+	}
+	if earliest := time.Now().Add(minRefreshInterval); next.Before(earliest) {
+		next = earliest
+	}
+	return next
+}
+
+// Run refreshes the token ahead of its expiry until the given context is cancelled.
+func (r *TokenRefresher) Run(ctx context.Context) error {
+	for {
+		tm := time.NewTimer(time.Until(r.nextAttempt()))
+		select {
+		case <-ctx.Done():
+			tm.Stop()
+			return nil
+		case <-tm.C:
+		}
+		tok, err := r.fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			r.setError(err)
+			expiry := r.Expiry()
+			if time.Now().Before(expiry) {
+				dlog.Warnf(ctx, "Unable to refresh the cluster token that expires at %s: %v", expiry.Format(time.RFC3339), err)
+			} else {
+				dlog.Errorf(ctx, "Unable to refresh the cluster token that expired at %s: %v", expiry.Format(time.RFC3339), err)
+			}
+			continue
+		}
+		r.set(tok)
+		dlog.Debugf(ctx, "Cluster token refreshed, it expires at %s", tok.Expiry.Format(time.RFC3339))
+	}
+}
+
+// WrapTransport returns a http.RoundTripper that adds the current token to all requests that don't have
+// an Authorization header.
+func (r *TokenRefresher) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &tokenRoundTripper{refresher: r, rt: rt}
+}
+
+type tokenRoundTripper struct {
+	refresher *TokenRefresher
+	rt        http.RoundTripper
+}
+
+func (t *tokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("Authorization") != "" {
+		return t.rt.RoundTrip(req)
+	}
+	t.refresher.RLock()
+	token := t.refresher.current.Value
+	t.refresher.RUnlock()
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.rt.RoundTrip(req)
+}
+
+func (t *tokenRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return t.rt
+}
+
+// execCredential runs the given credential plugin and returns the bearer token that it produces. The
+// errClientCertificate error is returned when the plugin produces a client certificate.
+func execCredential(ctx context.Context, ec *api.ExecConfig) (*Token, error) {
+	cmd := proc.CommandContext(ctx, ec.Command, ec.Args...)
+	cmd.DisableLogging = true
+	cmd.Env = os.Environ()
+	for _, e := range ec.Env {
+		cmd.Env = append(cmd.Env, e.Name+"="+e.Value)
+	}
+	execInfo, err := json.Marshal(map[string]any{
+		"apiVersion": ec.APIVersion,
+		"kind":       "ExecCredential",
+		"spec":       map[string]any{"interactive": false},
+	})
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = append(cmd.Env, "KUBERNETES_EXEC_INFO="+string(execInfo))
+	stderr := bytes.Buffer{}
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w: %s", ec.Command, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	var cred struct {
+		Status struct {
+			Token                 string    `json:"token"`
+			ExpirationTimestamp   time.Time `json:"expirationTimestamp"`
+			ClientCertificateData string    `json:"clientCertificateData"`
+		} `json:"status"`
+	}
+	if err = json.Unmarshal(out, &cred); err != nil {
+		return nil, fmt.Errorf("unable to parse the output of %s: %w", ec.Command, err)
+	}
+	if cred.Status.ClientCertificateData != "" {
+		return nil, errClientCertificate
+	}
+	if cred.Status.Token == "" {
+		return nil, fmt.Errorf("%s did not produce a bearer token", ec.Command)
+	}
+	return &Token{Value: cred.Status.Token, Expiry: cred.Status.ExpirationTimestamp}, nil
+}
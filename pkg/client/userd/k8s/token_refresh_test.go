@@ -0,0 +1,224 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/datawire/dlib/dlog"
+)
+
+// tokenSource hands out tokens with the given lifetime and records when they were fetched.
+type tokenSource struct {
+	sync.Mutex
+	lifetime time.Duration
+	fetches  []time.Time
+	fail     bool
+}
+
+func (ts *tokenSource) fetch(context.Context) (*Token, error) {
+	ts.Lock()
+	defer ts.Unlock()
+	if ts.fail {
+		return nil, errors.New("identity provider unavailable")
+	}
+	now := time.Now()
+	ts.fetches = append(ts.fetches, now)
+	return &Token{Value: fmt.Sprintf("token-%d", len(ts.fetches)), Expiry: now.Add(ts.lifetime)}, nil
+}
+
+func (ts *tokenSource) fetchCount() int {
+	ts.Lock()
+	defer ts.Unlock()
+	return len(ts.fetches)
+}
+
+func newTestRefresher(t *testing.T, ts *tokenSource) *TokenRefresher {
+	defer func(d time.Duration) { t.Cleanup(func() { minRefreshInterval = d }) }(minRefreshInterval)
+	minRefreshInterval = 10 * time.Millisecond
+	r := newTokenRefresher(ts.fetch)
+	tok, err := r.fetch(context.Background())
+	require.NoError(t, err)
+	r.set(tok)
+	return r
+}
+
+func TestTokenRefresher_refreshesBeforeExpiry(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+
+	ts := &tokenSource{lifetime: time.Second}
+	r := newTestRefresher(t, ts)
+	firstExpiry := r.Expiry()
+
+	var requestTokens []string
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		requestTokens = append(requestTokens, req.Header.Get("Authorization"))
+		mu.Unlock()
+	}))
+	defer srv.Close()
+	hc := &http.Client{Transport: r.WrapTransport(http.DefaultTransport)}
+	get := func() {
+		rsp, err := hc.Get(srv.URL)
+		require.NoError(t, err)
+		rsp.Body.Close()
+	}
+
+	get()
+	go func() { _ = r.Run(ctx) }()
+	require.Eventually(t, func() bool { return ts.fetchCount() > 1 }, 2*time.Second, 10*time.Millisecond)
+
+	ts.Lock()
+	refreshed := ts.fetches[1]
+	ts.Unlock()
+	assert.True(t, refreshed.Before(firstExpiry), "token refreshed at %s, after it expired at %s", refreshed, firstExpiry)
+	assert.True(t, r.Expiry().After(firstExpiry))
+	assert.NoError(t, r.LastError())
+
+	get()
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-2"}, requestTokens)
+}
+
+func TestTokenRefresher_failedRefresh(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+
+	ts := &tokenSource{lifetime: 400 * time.Millisecond}
+	r := newTestRefresher(t, ts)
+	ts.Lock()
+	ts.fail = true
+	ts.Unlock()
+
+	go func() { _ = r.Run(ctx) }()
+	require.Eventually(t, func() bool { return r.LastError() != nil }, time.Second, 10*time.Millisecond)
+	assert.Contains(t, r.LastError().Error(), "identity provider unavailable")
+
+	// The refresher keeps trying, and recovers when the token can be obtained again.
+	ts.Lock()
+	ts.fail = false
+	ts.Unlock()
+	require.Eventually(t, func() bool { return r.LastError() == nil }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, ts.fetchCount())
+}
+
+func TestTokenRefresher_nextAttempt(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		obtained time.Time
+		expiry   time.Time
+		err      error
+		want     time.Duration
+	}{
+		{"ahead of expiry", now, now.Add(time.Hour), nil, time.Hour - maxRefreshMargin},
+		{"expired", now.Add(-time.Hour), now.Add(-time.Minute), nil, minRefreshInterval},
+		{"expiry before obtained", now, now.Add(-time.Second), nil, minRefreshInterval},
+		{"expires within the margin", now, now.Add(time.Second), nil, minRefreshInterval},
+		{"retry", now, now.Add(time.Hour), errors.New("boom"), maxRetryInterval},
+		{"retry expiry before obtained", now, now.Add(-time.Second), errors.New("boom"), minRefreshInterval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTokenRefresher(nil)
+			r.current = &Token{Value: "abc", Expiry: tt.expiry}
+			r.obtained = tt.obtained
+			r.lastError = tt.err
+			assert.WithinDuration(t, now.Add(tt.want), r.nextAttempt(), time.Second)
+		})
+	}
+}
+
+func TestTokenRefresher_keepsExistingAuthorization(t *testing.T) {
+	r := newTestRefresher(t, &tokenSource{lifetime: time.Hour})
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		got = req.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	rsp, err := r.WrapTransport(http.DefaultTransport).RoundTrip(req)
+	require.NoError(t, err)
+	rsp.Body.Close()
+	assert.Equal(t, "Basic dXNlcjpwYXNz", got)
+}
+
+func TestNewTokenRefresher(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential plugin is a shell script")
+	}
+	ctx := dlog.NewTestContext(t, false)
+	plugin := func(t *testing.T, status string) *api.ExecConfig {
+		script := filepath.Join(t.TempDir(), "credential-plugin")
+		require.NoError(t, os.WriteFile(script, []byte(fmt.Sprintf(`#!/bin/sh
+cat <<EOF
+{"apiVersion":"client.authentication.k8s.io/v1beta1","kind":"ExecCredential","status":%s}
+EOF
+`, status)), 0o755))
+		return &api.ExecConfig{
+			APIVersion:      "client.authentication.k8s.io/v1beta1",
+			Command:         script,
+			InteractiveMode: api.NeverExecInteractiveMode,
+		}
+	}
+
+	t.Run("expiring token", func(t *testing.T) {
+		expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		rc := &rest.Config{ExecProvider: plugin(t, fmt.Sprintf(`{"token":"abc","expirationTimestamp":%q}`, expiry.Format(time.RFC3339)))}
+		r := NewTokenRefresher(ctx, rc)
+		require.NotNil(t, r)
+		assert.Nil(t, rc.ExecProvider)
+		assert.NotNil(t, rc.WrapTransport)
+		assert.True(t, expiry.Equal(r.Expiry()))
+	})
+
+	t.Run("token without expiry", func(t *testing.T) {
+		rc := &rest.Config{ExecProvider: plugin(t, `{"token":"abc"}`)}
+		assert.Nil(t, NewTokenRefresher(ctx, rc))
+		assert.NotNil(t, rc.ExecProvider)
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		rc := &rest.Config{ExecProvider: plugin(t, `{"clientCertificateData":"xyz"}`)}
+		assert.Nil(t, NewTokenRefresher(ctx, rc))
+		assert.NotNil(t, rc.ExecProvider)
+	})
+
+	t.Run("client certificate", func(t *testing.T) {
+		expiry := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		rc := &rest.Config{ExecProvider: plugin(t, fmt.Sprintf(`{"token":"abc","clientCertificateData":"xyz","clientKeyData":"xyz","expirationTimestamp":%q}`,
+			expiry.Format(time.RFC3339)))}
+		assert.Nil(t, NewTokenRefresher(ctx, rc))
+		assert.NotNil(t, rc.ExecProvider)
+		assert.Nil(t, rc.WrapTransport)
+	})
+
+	t.Run("auth-provider", func(t *testing.T) {
+		rc := &rest.Config{AuthProvider: &api.AuthProviderConfig{Name: "oidc", Config: map[string]string{"id-token": "abc"}}}
+		assert.Nil(t, NewTokenRefresher(ctx, rc))
+		assert.NotNil(t, rc.AuthProvider)
+		assert.Nil(t, rc.WrapTransport)
+	})
+
+	t.Run("no plugin", func(t *testing.T) {
+		assert.Nil(t, NewTokenRefresher(ctx, &rest.Config{BearerToken: "abc"}))
+	})
+}
@@ -0,0 +1,129 @@
+package userd
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+
+	"github.com/datawire/dlib/dhttp"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+)
+
+// Values of the state label of the proxy state transitions counter.
+const (
+	proxyStateConnected    = "connected"
+	proxyStateDisconnected = "disconnected"
+	proxyStateFailed       = "failed"
+	proxyStateReconnecting = "reconnecting"
+)
+
+// metrics are the Prometheus metrics of the connector. They are collected during the lifetime of the
+// connector, but only served when a session is created with a metrics listen address.
+type metrics struct {
+	registry              *prometheus.Registry
+	proxyStateTransitions *prometheus.CounterVec
+	rpcErrors             *prometheus.CounterVec
+}
+
+func newMetrics(activeIntercepts func() float64) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		proxyStateTransitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "telepresence_connector_proxy_state_transitions_total",
+			Help: "Number of times that the connection to the cluster entered a state",
+		}, []string{"state"}),
+		rpcErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "telepresence_connector_rpc_errors_total",
+			Help: "Number of gRPC calls to the connector that returned an error",
+		}, []string{"method"}),
+	}
+	m.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "telepresence_connector_active_intercepts",
+			Help: "Number of intercepts in the current session",
+		}, activeIntercepts),
+		m.proxyStateTransitions,
+		m.rpcErrors,
+	)
+	return m
+}
+
+// proxyStateChanged counts a transition into the given state.
+func (m *metrics) proxyStateChanged(state string) {
+	if m != nil {
+		m.proxyStateTransitions.WithLabelValues(state).Inc()
+	}
+}
+
+func (m *metrics) rpcError(method string, err error) {
+	if m != nil && err != nil {
+		m.rpcErrors.WithLabelValues(method).Inc()
+	}
+}
+
+func (m *metrics) unaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		rsp, err := handler(ctx, req)
+		m.rpcError(info.FullMethod, err)
+		return rsp, err
+	}
+}
+
+func (m *metrics) streamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		m.rpcError(info.FullMethod, err)
+		return err
+	}
+}
+
+// metricsListenAddress returns the given address, with the host set to localhost unless it is explicitly given.
+// A plain port number is accepted too.
+func metricsListenAddress(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		// Plain port number?
+		if _, err2 := net.LookupPort("tcp", addr); err2 != nil {
+			return "", errcat.User.Newf("invalid metrics listen address %q: %w", addr, err)
+		}
+		host, port = "", addr
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// listen opens the listener that serve will use. It's opened before the session is created, so that
+// a connect with an unusable address fails without side effects.
+func (m *metrics) listen(ctx context.Context, addr string) (net.Listener, error) {
+	addr, err := metricsListenAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+	l, err := (&net.ListenConfig{}).Listen(ctx, "tcp", addr)
+	if err != nil {
+		return nil, errcat.User.Newf("unable to serve metrics: %w", err)
+	}
+	return l, nil
+}
+
+// serve serves the metrics on the given listener until the given context is cancelled.
+func (m *metrics) serve(ctx context.Context, l net.Listener) {
+	dlog.Infof(ctx, "Serving metrics on http://%s/metrics", l.Addr())
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	sc := &dhttp.ServerConfig{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := sc.Serve(ctx, l); err != nil && ctx.Err() == nil {
+		dlog.Errorf(ctx, "metrics server ended with: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package userd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/datawire/dlib/dlog"
+)
+
+func Test_metricsListenAddress(t *testing.T) {
+	tests := []struct {
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{addr: "127.0.0.1:9900", want: "127.0.0.1:9900"},
+		{addr: ":9900", want: "127.0.0.1:9900"},
+		{addr: "9900", want: "127.0.0.1:9900"},
+		{addr: "0.0.0.0:9900", want: "0.0.0.0:9900"},
+		{addr: "[::1]:9900", want: "[::1]:9900"},
+		{addr: "nope", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.addr, func(t *testing.T) {
+			got, err := metricsListenAddress(tt.addr)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(dlog.NewTestContext(t, false))
+	defer cancel()
+
+	m := newMetrics(func() float64 { return 2 })
+	m.proxyStateChanged(proxyStateConnected)
+	m.proxyStateChanged(proxyStateDisconnected)
+	m.proxyStateChanged(proxyStateConnected)
+
+	unary := m.unaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/telepresence.connector.Connector/CreateIntercept"}
+	_, _ = unary(ctx, nil, info, func(context.Context, any) (any, error) { return nil, errors.New("boom") })
+	_, _ = unary(ctx, nil, info, func(context.Context, any) (any, error) { return nil, nil })
+
+	l, err := m.listen(ctx, ":0")
+	require.NoError(t, err)
+	go m.serve(ctx, l)
+
+	rsp, err := http.Get("http://" + l.Addr().String() + "/metrics")
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	body, err := io.ReadAll(rsp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), "telepresence_connector_active_intercepts 2\n")
+	assert.Contains(t, string(body), `telepresence_connector_proxy_state_transitions_total{state="connected"} 2`)
+	assert.Contains(t, string(body), `telepresence_connector_proxy_state_transitions_total{state="disconnected"} 1`)
+	assert.Contains(t, string(body),
+		`telepresence_connector_rpc_errors_total{method="/telepresence.connector.Connector/CreateIntercept"} 1`)
+}
+
+func TestMetrics_nil(t *testing.T) {
+	// A Service that doesn't run in a connector daemon has no metrics.
+	var m *metrics
+	assert.NotPanics(t, func() {
+		m.proxyStateChanged(proxyStateConnected)
+		m.rpcError("/x", errors.New("boom"))
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
 	"sync"
@@ -12,6 +13,8 @@ import (
 	"github.com/spf13/cobra"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/datawire/dlib/dgroup"
 	"github.com/datawire/dlib/dhttp"
@@ -23,12 +26,14 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/a8rcloud"
 	"github.com/telepresenceio/telepresence/v2/pkg/client"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/cli/cliutil"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/logging"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/auth"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/internal/broadcastqueue"
 	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/trafficmgr"
 	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
 	"github.com/telepresenceio/telepresence/v2/pkg/log"
 	"github.com/telepresenceio/telepresence/v2/pkg/tracing"
 )
@@ -75,6 +80,9 @@ type Service struct {
 
 	scout *scout.Reporter
 
+	// metrics is nil unless the service runs in a connector daemon
+	metrics *metrics
+
 	quit func()
 
 	session        trafficmgr.Session
@@ -82,6 +90,12 @@ type Service struct {
 	sessionContext context.Context
 	sessionLock    sync.RWMutex
 
+	// reconnecting is true while a session that lost its connection to the traffic-manager is being
+	// replaced. The sessionCancel will then cancel the reconnect. The reconnectError is the error of
+	// the last failed attempt.
+	reconnecting   bool
+	reconnectError string
+
 	// These are used to communicate between the various goroutines.
 	connectRequest  chan *rpc.ConnectRequest // server-grpc.connect() -> connectWorker
 	connectResponse chan *rpc.ConnectInfo    // connectWorker -> server-grpc.connect()
@@ -125,9 +139,11 @@ func Command(getCommands CommandFactory, daemonServices []DaemonService, session
 		Hidden: true,
 		Long:   help,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return run(cmd.Context(), getCommands, daemonServices, sessionServices)
+			opts := logging.OptionsFromFlags(cmd.Flags())
+			return run(logging.WithOptions(cmd.Context(), &opts), getCommands, daemonServices, sessionServices)
 		},
 	}
+	logging.AddFlags(c.Flags())
 	return c
 }
 
@@ -155,25 +171,34 @@ nextSession:
 		case cr = <-s.connectRequest:
 		}
 
-		var session trafficmgr.Session
 		var rsp *rpc.ConnectInfo
+		var replaced, recreate []*manager.InterceptInfo
+		if cr.SwitchContext {
+			rsp, replaced = s.switchSession(cr)
+		}
 
 		s.sessionLock.Lock() // Locked during creation
-		if c.Err() == nil {  // If by the time we've got the session lock we're cancelled, then don't create the session and just leave by way of the select below
+		if rsp == nil && s.reconnecting {
+			// An explicit connect replaces the session that is being reconnected.
+			s.sessionCancel()
+			s.sessionCancel = nil
+			s.reconnecting = false
+		}
+		// If by the time we've got the session lock we're cancelled, then don't create the session and just leave by way of
+		// the select below. A response from switchSession means that no session should be created either.
+		if rsp == nil && c.Err() == nil {
 			if s.session != nil {
 				// UpdateStatus sets rpc.ConnectInfo_ALREADY_CONNECTED if successful
 				rsp = s.session.UpdateStatus(s.sessionContext, cr)
 			} else {
-				sCtx, sCancel := context.WithCancel(c)
-				sCtx, session, rsp = trafficmgr.NewSession(sCtx, s.scout, cr, s, sessionServices)
-				sCtx = a8rcloud.WithSystemAPool[*SessionClient](sCtx, a8rcloud.UserdConnName, &SessionClientProvider{session})
-				if sCtx.Err() == nil && rsp.Error == rpc.ConnectInfo_UNSPECIFIED {
-					s.sessionContext = session.WithK8sInterface(sCtx)
-					s.sessionCancel = sCancel
-					s.session = session
-				} else {
-					sCancel()
-				}
+				rsp = s.newSession(c, cr, sessionServices)
+			}
+		}
+		if len(replaced) > 0 && rsp != nil {
+			if rsp.Error == rpc.ConnectInfo_UNSPECIFIED {
+				recreate, rsp.DroppedIntercepts = recreatableIntercepts(s.sessionContext, replaced)
+			} else {
+				rsp.DroppedIntercepts = replaced
 			}
 		}
 		s.sessionLock.Unlock()
@@ -195,9 +220,22 @@ nextSession:
 		// Run the session asynchronously. We must be able to respond to connect (with UpdateStatus) while
 		// the session is running. The s.sessionCancel is called from Disconnect
 		wg.Add(1)
-		go func(cr *rpc.ConnectRequest) {
+		go func(cr *rpc.ConnectRequest, recreate []*manager.InterceptInfo) {
 			defer wg.Done()
-			if err := s.session.Run(s.sessionContext); err != nil {
+			s.sessionLock.RLock()
+			session, sessionContext := s.session, s.sessionContext
+			s.sessionLock.RUnlock()
+			if session != nil && len(recreate) > 0 {
+				// The intercepts of a session that was replaced due to a switch_context request are
+				// recreated concurrently with the new session's Run, because that's where intercepts
+				// become active.
+				go recreateIntercepts(sessionContext, session, recreate)
+			}
+			for session != nil {
+				err := session.Run(sessionContext)
+				if err == nil {
+					return
+				}
 				if errors.Is(err, trafficmgr.SessionExpiredErr) {
 					// Session has expired. We need to cancel the owner session and reconnect
 					dlog.Info(c, "refreshing session")
@@ -208,17 +246,239 @@ nextSession:
 					}
 					return
 				}
-
+				if errors.Is(err, trafficmgr.SessionDurationElapsedErr) {
+					// The time-boxed session has ended. The user must connect again to start a new one.
+					dlog.Info(c, "ending session")
+					s.cancelSession()
+					return
+				}
+				if errors.Is(err, trafficmgr.ConnectionLostErr) {
+					session, sessionContext = s.reconnect(c, cr, sessionServices)
+					continue
+				}
 				dlog.Error(c, err)
+				return
 			}
-		}(cr)
+		}(cr, recreate)
 	}
 	wg.Wait()
 	return nil
 }
 
+// newSession creates a new session for the given request and makes it the current session. The caller
+// must hold the sessionLock.
+func (s *Service) newSession(c context.Context, cr *rpc.ConnectRequest, sessionServices []trafficmgr.SessionService) *rpc.ConnectInfo {
+	ss, rsp := s.startSession(c, cr, sessionServices)
+	if ss != nil {
+		s.session, s.sessionContext, s.sessionCancel = ss.session, ss.ctx, ss.cancel
+	}
+	return rsp
+}
+
+// startedSession is a session that has been created by startSession.
+type startedSession struct {
+	session trafficmgr.Session
+	ctx     context.Context
+	cancel  context.CancelFunc
+}
+
+// startSession creates a new session for the given request and, when requested, starts serving metrics for
+// the lifetime of that session. A nil session is returned when the response is an error.
+func (s *Service) startSession(c context.Context, cr *rpc.ConnectRequest, sessionServices []trafficmgr.SessionService) (*startedSession, *rpc.ConnectInfo) {
+	var metricsListener net.Listener
+	if cr.MetricsListen != "" {
+		var err error
+		if metricsListener, err = s.metrics.listen(c, cr.MetricsListen); err != nil {
+			s.metrics.proxyStateChanged(proxyStateFailed)
+			return nil, &rpc.ConnectInfo{
+				Error:         rpc.ConnectInfo_DAEMON_FAILED,
+				ErrorText:     err.Error(),
+				ErrorCategory: int32(errcat.GetCategory(err)),
+			}
+		}
+	}
+
+	sCtx, sCancel := context.WithCancel(c)
+	sCtx, session, rsp := trafficmgr.NewSession(sCtx, s.scout, cr, s, sessionServices)
+	sCtx = a8rcloud.WithSystemAPool[*SessionClient](sCtx, a8rcloud.UserdConnName, &SessionClientProvider{session})
+	if sCtx.Err() != nil || rsp.Error != rpc.ConnectInfo_UNSPECIFIED {
+		sCancel()
+		if metricsListener != nil {
+			_ = metricsListener.Close()
+		}
+		s.metrics.proxyStateChanged(proxyStateFailed)
+		return nil, rsp
+	}
+	s.metrics.proxyStateChanged(proxyStateConnected)
+	if metricsListener != nil {
+		go s.metrics.serve(sCtx, metricsListener)
+	}
+	return &startedSession{session: session, ctx: session.WithK8sInterface(sCtx), cancel: sCancel}, rsp
+}
+
+// reconnectBackoff and reconnectMaxBackoff control the delay between attempts to reconnect a session. The
+// delay starts at reconnectBackoff and is doubled after each failed attempt.
+var (
+	reconnectBackoff    = time.Second
+	reconnectMaxBackoff = 30 * time.Second
+)
+
+// reconnect replaces the current session, which has lost its connection to the traffic-manager, with a new
+// session created from the same connect request, and then recreates the intercepts that the new session
+// lacks. Attempts are retried with backoff until one succeeds or the reconnect is cancelled by Disconnect,
+// Quit, or a new connect. The new session is returned, or nil when the reconnect was cancelled.
+func (s *Service) reconnect(c context.Context, cr *rpc.ConnectRequest, sessionServices []trafficmgr.SessionService) (trafficmgr.Session, context.Context) {
+	s.sessionLock.Lock()
+	if s.session == nil {
+		// Disconnected while the session was failing
+		s.sessionLock.Unlock()
+		return nil, nil
+	}
+	status := s.session.Status(s.sessionContext)
+	dlog.Warnf(c, "Connection to the traffic-manager lost. Reconnecting")
+	rc, rCancel := context.WithCancel(c)
+
+	// The intercepts are not cleared. The traffic-manager keeps them if the new session is able to resume
+	// the lost one, and removes them when the lost session expires.
+	s.sessionCancel()
+	s.session = nil
+	s.sessionCancel = rCancel
+	s.reconnecting = true
+	s.reconnectError = ""
+	s.metrics.proxyStateChanged(proxyStateReconnecting)
+	s.sessionLock.Unlock()
+
+	if se := status.GetSessionExpiry(); se != nil {
+		// The new session must end when the lost one would have ended.
+		remaining := time.Until(se.AsTime())
+		if remaining <= 0 {
+			s.cancelSession()
+			return nil, nil
+		}
+		cr = proto.Clone(cr).(*rpc.ConnectRequest)
+		cr.SessionDuration = durationpb.New(remaining)
+	}
+
+	backoff := reconnectBackoff
+	for {
+		select {
+		case <-rc.Done():
+			return nil, nil
+		case <-time.After(backoff):
+		}
+
+		// The attempt is made without holding the sessionLock, so that Status can report that the session
+		// is reconnecting, and Disconnect can cancel the attempt.
+		ss, rsp := s.startSession(rc, cr, sessionServices)
+		s.sessionLock.Lock()
+		if rc.Err() != nil {
+			s.sessionLock.Unlock()
+			if ss != nil {
+				ss.cancel()
+			}
+			return nil, nil
+		}
+		if ss != nil {
+			// The sessionCancel is kept. It cancels the rc, and hence also the new session.
+			s.session, s.sessionContext = ss.session, ss.ctx
+			s.reconnecting = false
+			s.reconnectError = ""
+			s.sessionLock.Unlock()
+			dlog.Info(c, "Reconnected to the traffic-manager")
+			if ss.session.Status(ss.ctx).GetSessionInfo().GetSessionId() != status.GetSessionInfo().GetSessionId() {
+				// The traffic-manager dropped the lost session along with its intercepts. They are recreated
+				// concurrently with the new session's Run, because that's where intercepts become active.
+				go recreateIntercepts(ss.ctx, ss.session, status.GetIntercepts().GetIntercepts())
+			}
+			return ss.session, ss.ctx
+		}
+		s.reconnectError = rsp.ErrorText
+		s.sessionLock.Unlock()
+		dlog.Warnf(c, "Reconnect failed, retrying in %s: %s", backoff, rsp.ErrorText)
+		if backoff *= 2; backoff > reconnectMaxBackoff {
+			backoff = reconnectMaxBackoff
+		}
+	}
+}
+
+// recreateIntercepts adds the given intercepts of a lost session to its replacement.
+func recreateIntercepts(c context.Context, session trafficmgr.Session, intercepts []*manager.InterceptInfo) {
+	for _, ii := range intercepts {
+		result, err := session.AddIntercept(c, &rpc.CreateInterceptRequest{
+			Spec:       ii.Spec,
+			MountPoint: ii.ClientMountPoint,
+		})
+		switch {
+		case err != nil:
+			dlog.Errorf(c, "Unable to recreate intercept %s: %v", ii.Spec.Name, err)
+		case result.Error != common.InterceptError_UNSPECIFIED:
+			dlog.Errorf(c, "Unable to recreate intercept %s: %s", ii.Spec.Name, result.Error)
+		default:
+			dlog.Infof(c, "Recreated intercept %s", ii.Spec.Name)
+		}
+	}
+}
+
+// recreatableIntercepts partitions the intercepts of a session that was replaced due to a switch_context
+// request into those that can be recreated in the new session, i.e. those whose workload exists in the
+// cluster of the given context, and those that must be dropped.
+func recreatableIntercepts(c context.Context, intercepts []*manager.InterceptInfo) (recreate, dropped []*manager.InterceptInfo) {
+	for _, ii := range intercepts {
+		spec := ii.Spec
+		if _, err := k8sapi.GetWorkload(c, spec.Agent, spec.Namespace, spec.WorkloadKind); err != nil {
+			dlog.Infof(c, "Unable to recreate intercept %s: %v", spec.Name, err)
+			dropped = append(dropped, ii)
+			continue
+		}
+		recreate = append(recreate, ii)
+	}
+	return recreate, dropped
+}
+
+// activeIntercepts returns the number of intercepts in the current session.
+func (s *Service) activeIntercepts() float64 {
+	s.sessionLock.RLock()
+	defer s.sessionLock.RUnlock()
+	if s.session == nil {
+		return 0
+	}
+	return float64(len(s.session.Status(s.sessionContext).GetIntercepts().GetIntercepts()))
+}
+
+// switchSession prepares for a connect request that asks for the context or namespace of an existing
+// session to be switched. The request is validated using the current session's UpdateStatus, which fails
+// if the requested context doesn't exist, before the current session is cancelled. A nil response is
+// returned when a new session must be created for the request, along with the intercepts that were
+// removed when the current session was cancelled, so that they can be recreated in the new session.
+func (s *Service) switchSession(cr *rpc.ConnectRequest) (*rpc.ConnectInfo, []*manager.InterceptInfo) {
+	s.sessionLock.RLock()
+	if s.session == nil {
+		s.sessionLock.RUnlock()
+		return nil, nil
+	}
+	rsp := s.session.UpdateStatus(s.sessionContext, cr)
+	if rsp.Error != rpc.ConnectInfo_MUST_RESTART {
+		// Invalid request, or no switch is needed
+		s.sessionLock.RUnlock()
+		return rsp, nil
+	}
+	dlog.Infof(s.sessionContext, "Switching from context %s to a new session", rsp.ClusterContext)
+	var replaced []*manager.InterceptInfo
+	if is := s.session.Status(s.sessionContext).Intercepts; is != nil {
+		replaced = is.Intercepts
+	}
+	s.sessionLock.RUnlock()
+	s.cancelSession()
+	return nil, replaced
+}
+
 func (s *Service) cancelSessionReadLocked() {
 	if s.sessionCancel != nil {
+		if s.session == nil {
+			// Cancels a reconnect
+			s.sessionCancel()
+			return
+		}
 		if err := s.session.ClearIntercepts(s.sessionContext); err != nil {
 			dlog.Errorf(s.sessionContext, "failed to clear intercepts: %v", err)
 		}
@@ -234,8 +494,12 @@ func (s *Service) cancelSession() {
 	// We have to cancel the session before we can acquire this write-lock, because we need any long-running RPCs
 	// that may be holding the RLock to die.
 	s.sessionLock.Lock()
+	if s.session != nil {
+		s.metrics.proxyStateChanged(proxyStateDisconnected)
+	}
 	s.session = nil
 	s.sessionCancel = nil
+	s.reconnecting = false
 	s.sessionLock.Unlock()
 }
 
@@ -280,6 +544,9 @@ func run(c context.Context, getCommands CommandFactory, daemonServices []DaemonS
 	dlog.Infof(c, "PID is %d", os.Getpid())
 	dlog.Info(c, "")
 
+	// Mount points that were recorded by a daemon that ended without cleaning up are no longer in use.
+	trafficmgr.CleanupStaleMountPoints(c)
+
 	// Don't bother calling 'conn.Close()', it should remain open until we shut down, and just
 	// prefer to let the OS close it when we exit.
 
@@ -301,6 +568,7 @@ func run(c context.Context, getCommands CommandFactory, daemonServices []DaemonS
 		timedLogLevel:     log.NewTimedLevel(cfg.LogLevels.UserDaemon.String(), log.SetLevel),
 		getCommands:       getCommands,
 	}
+	s.metrics = newMetrics(s.activeIntercepts)
 	if err := logging.LoadTimedLevelFromCache(c, s.timedLogLevel, s.procName); err != nil {
 		return err
 	}
@@ -313,8 +581,8 @@ func run(c context.Context, getCommands CommandFactory, daemonServices []DaemonS
 
 	g.Go("server-grpc", func(c context.Context) (err error) {
 		opts := []grpc.ServerOption{
-			grpc.UnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
-			grpc.StreamInterceptor(otelgrpc.StreamServerInterceptor()),
+			grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor(), s.metrics.unaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor(), s.metrics.streamServerInterceptor()),
 		}
 		cfg := client.GetConfig(c)
 		if !cfg.Grpc.MaxReceiveSize.IsZero() {
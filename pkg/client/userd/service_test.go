@@ -0,0 +1,107 @@
+package userd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/datawire/dlib/dlog"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/trafficmgr"
+	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+)
+
+func newReconnectTestService(t *testing.T) (context.Context, *Service) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx = filelocation.WithUserHomeDir(ctx, t.TempDir())
+	ctx = filelocation.WithAppUserConfigDir(ctx, t.TempDir())
+	env, err := client.LoadEnv(ctx)
+	require.NoError(t, err)
+	ctx = client.WithEnv(ctx, env)
+	cfg, err := client.LoadConfig(ctx)
+	require.NoError(t, err)
+	ctx = client.WithConfig(ctx, cfg)
+
+	s := &Service{scout: scout.NewReporter(ctx, "reconnect-test"), ManagerProxy: trafficmgr.NewManagerProxy()}
+	s.sessionLock.Lock()
+	rsp := s.newSession(ctx, &rpc.ConnectRequest{Demo: true}, nil)
+	s.sessionLock.Unlock()
+	require.Equal(t, rpc.ConnectInfo_UNSPECIFIED, rsp.Error, rsp.ErrorText)
+	return ctx, s
+}
+
+func TestService_reconnect(t *testing.T) {
+	defer func(b time.Duration) { reconnectBackoff = b }(reconnectBackoff)
+
+	t.Run("replaces session", func(t *testing.T) {
+		reconnectBackoff = time.Millisecond
+		ctx, s := newReconnectTestService(t)
+		lost := s.session
+		session, sessionContext := s.reconnect(ctx, &rpc.ConnectRequest{Demo: true}, nil)
+		require.NotNil(t, session)
+		assert.NotSame(t, lost, session)
+		assert.Equal(t, session, s.session)
+		assert.NoError(t, sessionContext.Err())
+
+		ci, err := s.Status(ctx, &empty.Empty{})
+		require.NoError(t, err)
+		assert.Equal(t, rpc.ConnectInfo_ALREADY_CONNECTED, ci.Error)
+
+		s.cancelSession()
+		assert.Error(t, sessionContext.Err())
+	})
+
+	t.Run("disconnect cancels", func(t *testing.T) {
+		reconnectBackoff = time.Hour
+		ctx, s := newReconnectTestService(t)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			session, _ := s.reconnect(ctx, &rpc.ConnectRequest{Demo: true}, nil)
+			assert.Nil(t, session)
+		}()
+
+		assert.Eventually(t, func() bool {
+			ci, err := s.Status(ctx, &empty.Empty{})
+			return err == nil && ci.Error == rpc.ConnectInfo_RECONNECTING
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, err := s.Disconnect(ctx, &empty.Empty{})
+		require.NoError(t, err)
+		<-done
+		ci, err := s.Status(ctx, &empty.Empty{})
+		require.NoError(t, err)
+		assert.Equal(t, rpc.ConnectInfo_DISCONNECTED, ci.Error)
+	})
+}
+
+func Test_recreatableIntercepts(t *testing.T) {
+	ctx, s := newReconnectTestService(t)
+	defer s.cancelSession()
+
+	ii := func(name, agent, namespace string) *manager.InterceptInfo {
+		return &manager.InterceptInfo{Spec: &manager.InterceptSpec{Name: name, Agent: agent, Namespace: namespace, WorkloadKind: "Deployment"}}
+	}
+	names := func(iis []*manager.InterceptInfo) []string {
+		ns := make([]string, len(iis))
+		for i, ii := range iis {
+			ns[i] = ii.Spec.Name
+		}
+		return ns
+	}
+	recreate, dropped := recreatableIntercepts(s.session.WithK8sInterface(ctx), []*manager.InterceptInfo{
+		ii("echo-easy", "echo-easy", "default"),
+		ii("missing", "missing", "default"),
+		ii("hello-other", "hello", "other"),
+		ii("hello", "hello", "default"),
+	})
+	assert.Equal(t, []string{"echo-easy", "hello"}, names(recreate))
+	assert.Equal(t, []string{"missing", "hello-other"}, names(dropped))
+}
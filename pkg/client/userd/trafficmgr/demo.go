@@ -0,0 +1,450 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	stacktrace "github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	empty "google.golang.org/protobuf/types/known/emptypb"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/datawire/dlib/dcontext"
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/connector"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentmap"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/auth"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/log"
+)
+
+// demoAgentPort is the default port of the traffic-agent.
+const demoAgentPort = 9900
+
+// newDemoSession creates a session that uses an in-process fake cluster and an in-process fake traffic-manager
+// instead of connecting to a real cluster. The session doesn't use the root daemon, so there's no outbound
+// connectivity, DNS, or volume mounts, but workloads can be listed and intercepted just like in a real session.
+func newDemoSession(c context.Context, sr *scout.Reporter, cr *rpc.ConnectRequest, svc Service, extraServices []SessionService) (context.Context, Session, *connector.ConnectInfo) {
+	dlog.Info(c, "Starting demo cluster...")
+	mappedNamespaces := cr.MappedNamespaces
+	if len(mappedNamespaces) == 1 && mappedNamespaces[0] == "all" {
+		mappedNamespaces = nil
+	} else {
+		sort.Strings(mappedNamespaces)
+	}
+	cluster, err := k8s.NewDemoCluster(c, mappedNamespaces)
+	if err != nil {
+		dlog.Errorf(c, "unable to start demo cluster: %+v", err)
+		return c, nil, connectError(rpc.ConnectInfo_CLUSTER_FAILED, err)
+	}
+	dlog.Infof(c, "Connected to demo cluster (%s)", cluster.Server)
+	c = cluster.WithK8sInterface(c)
+
+	tmgr, err := connectDemoMgr(c, cluster, sr.InstallID())
+	if err != nil {
+		dlog.Errorf(c, "Unable to start demo traffic-manager: %s", err)
+		return c, nil, connectError(rpc.ConnectInfo_TRAFFIC_MANAGER_FAILED, err)
+	}
+	tmgr.sessionServices = extraServices
+	tmgr.sr = sr
+	svc.SetManagerClient(tmgr.managerClient)
+
+	ret := &rpc.ConnectInfo{
+		Error:          rpc.ConnectInfo_UNSPECIFIED,
+		ClusterContext: cluster.Config.Context,
+		ClusterServer:  cluster.Config.Server,
+		ClusterId:      cluster.GetClusterId(c),
+		SessionInfo:    tmgr.session(),
+		Intercepts:     &manager.InterceptInfoSnapshot{},
+	}
+	c = dlog.WithField(c, log.SessionIDKey, ret.SessionInfo.GetSessionId())
+	c = WithSession(c, tmgr)
+	return c, tmgr, ret
+}
+
+// connectDemoMgr starts a demoManager and returns a TrafficManager that is connected to it.
+func connectDemoMgr(c context.Context, cluster *k8s.Cluster, installID string) (*TrafficManager, error) {
+	userinfo, err := user.Current()
+	if err != nil {
+		return nil, stacktrace.Wrap(err, "user.Current()")
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return nil, stacktrace.Wrap(err, "os.Hostname()")
+	}
+	ti, err := NewTrafficManagerInstaller(cluster)
+	if err != nil {
+		return nil, stacktrace.Wrap(err, "new installer")
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	srv := grpc.NewServer()
+	manager.RegisterManagerServer(srv, newDemoManager(k8sapi.GetK8sInterface(c), cluster.GetManagerNamespace(), managerImageName(c)))
+	go func() {
+		if err := srv.Serve(l); err != nil {
+			dlog.Errorf(c, "demo traffic-manager failed: %v", err)
+		}
+	}()
+
+	conn, err := grpc.DialContext(c, l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		srv.Stop()
+		return nil, fmt.Errorf("dial manager: %w", err)
+	}
+	go func() {
+		<-c.Done()
+		// The session departs from the manager when it ends, so give it a chance to do that before the
+		// server stops.
+		wc, cancel := context.WithTimeout(dcontext.WithoutCancel(c), 5*time.Second)
+		defer cancel()
+		for st := conn.GetState(); st != connectivity.Shutdown && conn.WaitForStateChange(wc, st); st = conn.GetState() {
+		}
+		srv.Stop()
+	}()
+
+	userAndHost := fmt.Sprintf("%s@%s", userinfo.Username, host)
+	mClient := manager.NewManagerClient(conn)
+	si, err := mClient.ArriveAsClient(c, &manager.ClientInfo{
+		Name:      userAndHost,
+		InstallId: installID,
+		Product:   "telepresence",
+		Version:   client.Version(),
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("manager.ArriveAsClient: %w", err)
+	}
+
+	// The demo manager implements the API of the traffic-manager that is released with this client, but
+	// development builds have no proper version.
+	managerVersion := client.Semver()
+	if managerVersion.LT(firstAgentConfigMapVersion) {
+		managerVersion = firstAgentConfigMapVersion
+	}
+
+	return &TrafficManager{
+		installer:   ti.(*installer),
+		installID:   installID,
+		userAndHost: userAndHost,
+		getCloudAPIKey: func(context.Context, string, bool) (string, error) {
+			return "", auth.ErrNotLoggedIn
+		},
+		managerClient:       mClient,
+		managerConn:         conn,
+		managerVersion:      managerVersion,
+		sessionInfo:         si,
+		localIntercepts:     map[string]string{},
+		currentInterceptors: map[string]int{},
+		wlWatcher:           newWASWatcher(),
+		demo:                true,
+	}, nil
+}
+
+// demoManager is an in-process stand-in for the traffic-manager. It never modifies the cluster. Instead, an
+// agent appears as soon as a workload is prepared for an intercept, and intercepts become active immediately.
+type demoManager struct {
+	manager.UnimplementedManagerServer
+	ki               kubernetes.Interface
+	managerNamespace string
+	agentImage       string
+
+	sync.Mutex
+	changed    chan struct{} // closed and replaced when the agents or intercepts change
+	sessions   map[string]struct{}
+	agents     map[string]*manager.AgentInfo     // keyed by <name>.<namespace>
+	intercepts map[string]*manager.InterceptInfo // keyed by intercept ID
+}
+
+func newDemoManager(ki kubernetes.Interface, managerNamespace, agentImage string) *demoManager {
+	return &demoManager{
+		ki:               ki,
+		managerNamespace: managerNamespace,
+		agentImage:       agentImage,
+		changed:          make(chan struct{}),
+		sessions:         make(map[string]struct{}),
+		agents:           make(map[string]*manager.AgentInfo),
+		intercepts:       make(map[string]*manager.InterceptInfo),
+	}
+}
+
+func (m *demoManager) notifyLocked() {
+	close(m.changed)
+	m.changed = make(chan struct{})
+}
+
+func (m *demoManager) checkSessionLocked(si *manager.SessionInfo) error {
+	if _, ok := m.sessions[si.GetSessionId()]; !ok {
+		return status.Errorf(codes.NotFound, "session %q not found", si.GetSessionId())
+	}
+	return nil
+}
+
+// watch calls send initially and then each time the agents or intercepts change, until the context is done.
+func (m *demoManager) watch(ctx context.Context, send func() error) error {
+	for {
+		m.Lock()
+		changed := m.changed
+		m.Unlock()
+		if err := send(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+func (m *demoManager) Version(context.Context, *empty.Empty) (*manager.VersionInfo2, error) {
+	return &manager.VersionInfo2{Version: client.Version()}, nil
+}
+
+func (m *demoManager) CanConnectAmbassadorCloud(context.Context, *empty.Empty) (*manager.AmbassadorCloudConnection, error) {
+	return &manager.AmbassadorCloudConnection{CanConnect: false}, nil
+}
+
+func (m *demoManager) GetTelepresenceAPI(context.Context, *empty.Empty) (*manager.TelepresenceAPIInfo, error) {
+	return &manager.TelepresenceAPIInfo{}, nil
+}
+
+func (m *demoManager) ArriveAsClient(_ context.Context, ci *manager.ClientInfo) (*manager.SessionInfo, error) {
+	m.Lock()
+	defer m.Unlock()
+	id := uuid.New().String()
+	m.sessions[id] = struct{}{}
+	installID := ci.InstallId
+	return &manager.SessionInfo{SessionId: id, InstallId: &installID}, nil
+}
+
+func (m *demoManager) Remain(_ context.Context, rr *manager.RemainRequest) (*empty.Empty, error) {
+	m.Lock()
+	defer m.Unlock()
+	if err := m.checkSessionLocked(rr.Session); err != nil {
+		return nil, err
+	}
+	return &empty.Empty{}, nil
+}
+
+func (m *demoManager) Depart(_ context.Context, si *manager.SessionInfo) (*empty.Empty, error) {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.sessions, si.SessionId)
+	for id, ii := range m.intercepts {
+		if ii.ClientSession.SessionId == si.SessionId {
+			delete(m.intercepts, id)
+		}
+	}
+	m.notifyLocked()
+	return &empty.Empty{}, nil
+}
+
+func (m *demoManager) agentsIn(namespaces []string) []*manager.AgentInfo {
+	m.Lock()
+	defer m.Unlock()
+	agents := make([]*manager.AgentInfo, 0, len(m.agents))
+	for _, ai := range m.agents {
+		if namespaces == nil {
+			agents = append(agents, ai)
+			continue
+		}
+		for _, ns := range namespaces {
+			if ai.Namespace == ns {
+				agents = append(agents, ai)
+				break
+			}
+		}
+	}
+	sort.Slice(agents, func(i, j int) bool { return agents[i].Name < agents[j].Name })
+	return agents
+}
+
+func (m *demoManager) WatchAgents(_ *manager.SessionInfo, stream manager.Manager_WatchAgentsServer) error {
+	return m.watch(stream.Context(), func() error {
+		return stream.Send(&manager.AgentInfoSnapshot{Agents: m.agentsIn(nil)})
+	})
+}
+
+func (m *demoManager) WatchAgentsNS(ar *manager.AgentsRequest, stream manager.Manager_WatchAgentsNSServer) error {
+	return m.watch(stream.Context(), func() error {
+		return stream.Send(&manager.AgentInfoSnapshot{Agents: m.agentsIn(ar.Namespaces)})
+	})
+}
+
+func (m *demoManager) WatchIntercepts(si *manager.SessionInfo, stream manager.Manager_WatchInterceptsServer) error {
+	return m.watch(stream.Context(), func() error {
+		m.Lock()
+		intercepts := make([]*manager.InterceptInfo, 0, len(m.intercepts))
+		for _, ii := range m.intercepts {
+			if ii.ClientSession.SessionId == si.SessionId {
+				intercepts = append(intercepts, ii)
+			}
+		}
+		m.Unlock()
+		sort.Slice(intercepts, func(i, j int) bool { return intercepts[i].Spec.Name < intercepts[j].Spec.Name })
+		return stream.Send(&manager.InterceptInfoSnapshot{Intercepts: intercepts})
+	})
+}
+
+// WatchDial never sends any dial requests, because there are no agents that could make them.
+func (m *demoManager) WatchDial(_ *manager.SessionInfo, stream manager.Manager_WatchDialServer) error {
+	<-stream.Context().Done()
+	return nil
+}
+
+func (m *demoManager) PrepareIntercept(ctx context.Context, cr *manager.CreateInterceptRequest) (*manager.PreparedIntercept, error) {
+	interceptError := func(err error) (*manager.PreparedIntercept, error) {
+		return &manager.PreparedIntercept{Error: err.Error(), ErrorCategory: int32(errcat.GetCategory(err))}, nil
+	}
+
+	spec := cr.InterceptSpec
+	ctx = k8sapi.WithK8sInterface(ctx, m.ki)
+	wl, err := k8sapi.GetWorkload(ctx, spec.Agent, spec.Namespace, spec.WorkloadKind)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			err = errcat.User.New(err)
+		}
+		return interceptError(err)
+	}
+	ac, err := agentmap.Generate(ctx, wl, &agentmap.GeneratorConfig{
+		AgentPort:           demoAgentPort,
+		QualifiedAgentImage: m.agentImage,
+		ManagerNamespace:    m.managerNamespace,
+	})
+	if err != nil {
+		return interceptError(err)
+	}
+	ic, err := findDemoIntercept(ac, spec)
+	if err != nil {
+		return interceptError(err)
+	}
+
+	m.Lock()
+	key := ac.AgentName + "." + ac.Namespace
+	if _, ok := m.agents[key]; !ok {
+		podName := fmt.Sprintf("%s-%s", ac.AgentName, uuid.New().String()[:8])
+		m.agents[key] = &manager.AgentInfo{
+			Name:      ac.AgentName,
+			Namespace: ac.Namespace,
+			PodIp:     fmt.Sprintf("10.42.0.%d", len(m.agents)+10),
+			Product:   "telepresence",
+			Version:   client.Version(),
+			Mechanisms: []*manager.AgentInfo_Mechanism{{
+				Name:    "tcp",
+				Product: "telepresence",
+				Version: client.Version(),
+			}},
+			Environment: map[string]string{"HOSTNAME": podName},
+		}
+		m.notifyLocked()
+	}
+	m.Unlock()
+
+	return &manager.PreparedIntercept{
+		Namespace:       spec.Namespace,
+		ServiceUid:      string(ic.ServiceUID),
+		ServiceName:     ic.ServiceName,
+		ServicePortName: ic.ServicePortName,
+		ServicePort:     int32(ic.ServicePort),
+		Protocol:        string(ic.Protocol),
+		AgentImage:      ac.AgentImage,
+		WorkloadKind:    ac.WorkloadKind,
+	}, nil
+}
+
+// findDemoIntercept returns the intercept of the given agent config that matches the service name and service
+// port identifier of the given spec.
+func findDemoIntercept(ac *agentconfig.Sidecar, spec *manager.InterceptSpec) (*agentconfig.Intercept, error) {
+	spi := agentconfig.PortIdentifier(spec.ServicePortIdentifier)
+	var found *agentconfig.Intercept
+	for _, cn := range ac.Containers {
+		for _, ic := range cn.Intercepts {
+			if !(spec.ServiceName == "" || spec.ServiceName == ic.ServiceName) || !(spi == "" || agentconfig.IsInterceptFor(spi, ic)) {
+				continue
+			}
+			if found != nil {
+				return nil, errcat.User.Newf("%s %s.%s has multiple interceptable service ports.\n"+
+					"Please specify the service and/or service port you want to intercept "+
+					"by passing the --service=<svc> and/or --port=<local:svcPortName> flag.",
+					ac.WorkloadKind, ac.WorkloadName, ac.Namespace)
+			}
+			found = ic
+		}
+	}
+	if found == nil {
+		return nil, errcat.User.Newf("%s %s.%s has no interceptable port matching service %q, port %q",
+			ac.WorkloadKind, ac.WorkloadName, ac.Namespace, spec.ServiceName, spi)
+	}
+	return found, nil
+}
+
+func (m *demoManager) CreateIntercept(_ context.Context, cr *manager.CreateInterceptRequest) (*manager.InterceptInfo, error) {
+	spec := cr.InterceptSpec
+	m.Lock()
+	defer m.Unlock()
+	if err := m.checkSessionLocked(cr.Session); err != nil {
+		return nil, err
+	}
+	id := cr.Session.SessionId + ":" + spec.Name
+	if _, ok := m.intercepts[id]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "Intercept named %q already exists", spec.Name)
+	}
+	ai, ok := m.agents[spec.Agent+"."+spec.Namespace]
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "no agent found for %s.%s", spec.Agent, spec.Namespace)
+	}
+	ii := &manager.InterceptInfo{
+		Spec:          spec,
+		Id:            id,
+		ClientSession: cr.Session,
+		Disposition:   manager.InterceptDispositionType_ACTIVE,
+		PodIp:         ai.PodIp,
+		Environment:   ai.Environment,
+	}
+	m.intercepts[id] = ii
+	m.notifyLocked()
+	return ii, nil
+}
+
+func (m *demoManager) GetIntercept(_ context.Context, gr *manager.GetInterceptRequest) (*manager.InterceptInfo, error) {
+	m.Lock()
+	defer m.Unlock()
+	if ii, ok := m.intercepts[gr.Session.GetSessionId()+":"+gr.Name]; ok {
+		return ii, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "Intercept named %q not found", gr.Name)
+}
+
+func (m *demoManager) RemoveIntercept(_ context.Context, rr *manager.RemoveInterceptRequest2) (*empty.Empty, error) {
+	m.Lock()
+	defer m.Unlock()
+	id := rr.Session.GetSessionId() + ":" + rr.Name
+	if _, ok := m.intercepts[id]; !ok {
+		return nil, status.Errorf(codes.NotFound, "Intercept named %q not found", rr.Name)
+	}
+	delete(m.intercepts, id)
+	m.notifyLocked()
+	return &empty.Empty{}, nil
+}
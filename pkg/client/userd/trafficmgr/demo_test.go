@@ -0,0 +1,122 @@
+package trafficmgr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/client"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/scout"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/userd/k8s"
+	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+)
+
+// demoTestService is a Service that only provides what a demo session needs.
+type demoTestService struct {
+	Service
+	managerClient manager.ManagerClient
+}
+
+func (s *demoTestService) SetManagerClient(mc manager.ManagerClient, _ ...grpc.CallOption) {
+	s.managerClient = mc
+}
+
+func newDemoTestContext(t *testing.T) context.Context {
+	ctx := dlog.NewTestContext(t, false)
+	ctx = filelocation.WithUserHomeDir(ctx, t.TempDir())
+	ctx = filelocation.WithAppUserConfigDir(ctx, t.TempDir())
+	env, err := client.LoadEnv(ctx)
+	require.NoError(t, err)
+	ctx = client.WithEnv(ctx, env)
+	cfg, err := client.LoadConfig(ctx)
+	require.NoError(t, err)
+	return client.WithConfig(ctx, cfg)
+}
+
+func workloadNames(t *testing.T, ctx context.Context, session Session, filter rpc.ListRequest_Filter) []string {
+	snapshot, err := session.WorkloadInfoSnapshot(ctx, []string{"default"}, filter, false)
+	require.NoError(t, err)
+	names := make([]string, len(snapshot.Workloads))
+	for i, wl := range snapshot.Workloads {
+		names[i] = wl.Name
+	}
+	return names
+}
+
+func TestDemoSession(t *testing.T) {
+	ctx, cancel := context.WithCancel(newDemoTestContext(t))
+	svc := &demoTestService{}
+	ctx, session, ci := NewSession(ctx, scout.NewReporter(ctx, "demo-test"), &rpc.ConnectRequest{Demo: true}, svc, nil)
+	require.Equal(t, rpc.ConnectInfo_UNSPECIFIED, ci.Error, ci.ErrorText)
+	assert.Equal(t, k8s.DemoContext, ci.ClusterContext)
+	assert.NotNil(t, svc.managerClient)
+
+	runDone := make(chan error)
+	go func() { runDone <- session.Run(ctx) }()
+	defer func() {
+		cancel()
+		assert.NoError(t, <-runDone)
+	}()
+
+	t.Run("serves sample workloads", func(t *testing.T) {
+		assert.Equal(t, []string{"echo-easy", "hello"}, workloadNames(t, ctx, session, rpc.ListRequest_INTERCEPTABLE))
+		assert.Empty(t, workloadNames(t, ctx, session, rpc.ListRequest_INTERCEPTS))
+	})
+
+	t.Run("intercept", func(t *testing.T) {
+		result, err := session.AddIntercept(ctx, &rpc.CreateInterceptRequest{
+			Spec: &manager.InterceptSpec{
+				Name:       "hello",
+				Agent:      "hello",
+				Namespace:  "default",
+				Mechanism:  "tcp",
+				TargetHost: "127.0.0.1",
+				TargetPort: 8080,
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, common.InterceptError_UNSPECIFIED, result.Error, result.ErrorText)
+		ii := result.InterceptInfo
+		require.NotNil(t, ii)
+		assert.Equal(t, manager.InterceptDispositionType_ACTIVE, ii.Disposition)
+		assert.Equal(t, "Deployment", ii.Spec.WorkloadKind)
+		assert.Equal(t, "hello", ii.Spec.ServiceName)
+		assert.NotEmpty(t, ii.PodIp)
+
+		assert.Equal(t, []string{"hello"}, workloadNames(t, ctx, session, rpc.ListRequest_INTERCEPTS))
+		assert.Equal(t, []string{"hello"}, workloadNames(t, ctx, session, rpc.ListRequest_INSTALLED_AGENTS))
+		status := session.Status(ctx)
+		require.Len(t, status.Intercepts.Intercepts, 1)
+		assert.Equal(t, "hello", status.Intercepts.Intercepts[0].Spec.Name)
+
+		require.NoError(t, session.RemoveIntercept(ctx, "hello"))
+		require.Eventually(t, func() bool {
+			return len(session.Status(ctx).Intercepts.Intercepts) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("intercept of unknown workload", func(t *testing.T) {
+		result, err := session.AddIntercept(ctx, &rpc.CreateInterceptRequest{
+			Spec: &manager.InterceptSpec{
+				Name:      "nope",
+				Agent:     "nope",
+				Namespace: "default",
+			},
+		})
+		require.NoError(t, err)
+		assert.NotEqual(t, common.InterceptError_UNSPECIFIED, result.Error)
+	})
+
+	t.Run("update status", func(t *testing.T) {
+		assert.Equal(t, rpc.ConnectInfo_ALREADY_CONNECTED, session.UpdateStatus(ctx, &rpc.ConnectRequest{Demo: true}).Error)
+		assert.Equal(t, rpc.ConnectInfo_MUST_RESTART, session.UpdateStatus(ctx, &rpc.ConnectRequest{}).Error)
+	})
+}
@@ -0,0 +1,96 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/proto"
+	errors2 "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	rpc "github.com/telepresenceio/telepresence/rpc/v2/connector"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/rpc/v2/userdaemon"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentmap"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+// dryRunAgentPort is the port that the traffic-manager assigns to the first intercepted port of an agent
+// unless it's been configured otherwise.
+const dryRunAgentPort = 9900
+
+// dryRunIntercept resolves the workload, service, and service port that an intercept for the given spec would
+// use, and whether a traffic-agent would be injected into the workload. Unlike the traffic-manager's
+// PrepareIntercept, it only reads from the cluster, so nothing is modified.
+func (tm *TrafficManager) dryRunIntercept(c context.Context, spec *manager.InterceptSpec) *rpc.InterceptResult {
+	wl, err := k8sapi.GetWorkload(c, spec.Agent, spec.Namespace, spec.WorkloadKind)
+	if err != nil {
+		if errors2.IsNotFound(err) {
+			return interceptError(common.InterceptError_NO_ACCEPTABLE_WORKLOAD, errcat.User.Newf(spec.Name))
+		}
+		return interceptError(common.InterceptError_FAILED_TO_ESTABLISH, err)
+	}
+	ac, err := dryRunAgentConfig(c, wl)
+	if err != nil {
+		return interceptError(common.InterceptError_MISCONFIGURED_WORKLOAD, errcat.User.New(err))
+	}
+	_, ic, err := agentconfig.FindIntercept(ac, spec)
+	if err != nil {
+		return interceptError(common.InterceptError_FAILED_TO_ESTABLISH, err)
+	}
+
+	spec = proto.Clone(spec).(*manager.InterceptSpec)
+	spec.Client = tm.userAndHost
+	spec.WorkloadKind = wl.GetKind()
+	spec.ServiceName = ic.ServiceName
+	if ic.ServicePortName != "" {
+		spec.ServicePortIdentifier = ic.ServicePortName
+	} else {
+		spec.ServicePortIdentifier = strconv.Itoa(int(ic.ServicePort))
+	}
+	if spec.Mechanism == "" {
+		spec.Mechanism = "tcp"
+	}
+	return &rpc.InterceptResult{
+		InterceptInfo: &manager.InterceptInfo{Spec: spec},
+		ServiceUid:    string(ic.ServiceUID),
+		WorkloadKind:  wl.GetKind(),
+		ServiceProps: &userdaemon.IngressInfoRequest{
+			ServiceUid:            string(ic.ServiceUID),
+			ServiceName:           ic.ServiceName,
+			ServicePortIdentifier: ic.ServicePortName,
+			ServicePort:           int32(ic.ServicePort),
+			Namespace:             wl.GetNamespace(),
+		},
+		AgentInjection: !tm.hasAgent(wl.GetName(), wl.GetNamespace()),
+	}
+}
+
+// dryRunAgentConfig returns the agent config for the given workload from the agents ConfigMap, or, if no
+// such config exists, the config that the traffic-manager would generate for it.
+func dryRunAgentConfig(c context.Context, wl k8sapi.Workload) (*agentconfig.Sidecar, error) {
+	cm, err := k8sapi.GetK8sInterface(c).CoreV1().ConfigMaps(wl.GetNamespace()).Get(c, agentconfig.ConfigMap, meta.GetOptions{})
+	if err != nil && !errors2.IsNotFound(err) {
+		return nil, fmt.Errorf("unable to get ConfigMap %s.%s: %w", agentconfig.ConfigMap, wl.GetNamespace(), err)
+	}
+	if err == nil {
+		if y, ok := cm.Data[wl.GetName()]; ok {
+			return agentconfig.UnmarshalConfigMapEntry(y, wl.GetName(), wl.GetNamespace())
+		}
+	}
+	return agentmap.Generate(c, wl, &agentmap.GeneratorConfig{AgentPort: dryRunAgentPort})
+}
+
+// hasAgent returns true if the traffic-manager knows about a traffic-agent for the given workload.
+func (tm *TrafficManager) hasAgent(name, namespace string) bool {
+	for _, a := range tm.getCurrentAgents() {
+		if a.Name == name && a.Namespace == namespace {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package trafficmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/common"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+func TestTrafficManager_dryRunIntercept(t *testing.T) {
+	labels := map[string]string{"app": "echo"}
+	clientset := fake.NewSimpleClientset(
+		&core.Service{
+			ObjectMeta: meta.ObjectMeta{Name: "echo", Namespace: "some-ns", UID: types.UID("echo-uid")},
+			Spec: core.ServiceSpec{
+				Ports: []core.ServicePort{
+					{Name: "http", Protocol: "TCP", Port: 80, TargetPort: intstr.FromString("http")},
+					{Name: "grpc", Protocol: "TCP", Port: 9090, TargetPort: intstr.FromInt(9090)},
+				},
+				Selector: labels,
+			},
+		},
+		&apps.StatefulSet{
+			TypeMeta:   meta.TypeMeta{Kind: "StatefulSet", APIVersion: "apps/v1"},
+			ObjectMeta: meta.ObjectMeta{Name: "echo", Namespace: "some-ns"},
+			Spec: apps.StatefulSetSpec{
+				Selector: &meta.LabelSelector{MatchLabels: labels},
+				Template: core.PodTemplateSpec{
+					ObjectMeta: meta.ObjectMeta{Labels: labels},
+					Spec: core.PodSpec{Containers: []core.Container{{
+						Name: "echo",
+						Ports: []core.ContainerPort{
+							{Name: "http", ContainerPort: 8080, Protocol: "TCP"},
+							{ContainerPort: 9090, Protocol: "TCP"},
+						},
+					}}},
+				},
+			},
+		},
+	)
+	ctx := k8sapi.WithK8sInterface(dlog.NewTestContext(t, false), clientset)
+	spec := func(portID string) *manager.InterceptSpec {
+		return &manager.InterceptSpec{
+			Name:                  "echo",
+			Agent:                 "echo",
+			Namespace:             "some-ns",
+			ServicePortIdentifier: portID,
+			TargetHost:            "127.0.0.1",
+			TargetPort:            8080,
+		}
+	}
+
+	t.Run("resolves port", func(t *testing.T) {
+		tm := &TrafficManager{}
+		r := tm.dryRunIntercept(ctx, spec("http"))
+		require.Equal(t, common.InterceptError_UNSPECIFIED, r.Error, r.ErrorText)
+		assert.Equal(t, "StatefulSet", r.WorkloadKind)
+		assert.Equal(t, "echo-uid", r.ServiceUid)
+		assert.Equal(t, "http", r.ServiceProps.ServicePortIdentifier)
+		assert.Equal(t, int32(80), r.ServiceProps.ServicePort)
+		assert.True(t, r.AgentInjection)
+
+		rs := r.InterceptInfo.Spec
+		assert.Equal(t, "echo", rs.ServiceName)
+		assert.Equal(t, "tcp", rs.Mechanism)
+		assert.Equal(t, int32(8080), rs.TargetPort)
+	})
+
+	t.Run("unnamed port", func(t *testing.T) {
+		tm := &TrafficManager{}
+		r := tm.dryRunIntercept(ctx, spec("9090"))
+		require.Equal(t, common.InterceptError_UNSPECIFIED, r.Error, r.ErrorText)
+		assert.Equal(t, "grpc", r.ServiceProps.ServicePortIdentifier)
+		assert.Equal(t, int32(9090), r.ServiceProps.ServicePort)
+	})
+
+	t.Run("agent already installed", func(t *testing.T) {
+		tm := &TrafficManager{currentAgents: []*manager.AgentInfo{{Name: "echo", Namespace: "some-ns"}}}
+		r := tm.dryRunIntercept(ctx, spec("http"))
+		require.Equal(t, common.InterceptError_UNSPECIFIED, r.Error, r.ErrorText)
+		assert.False(t, r.AgentInjection)
+	})
+
+	t.Run("ambiguous port", func(t *testing.T) {
+		tm := &TrafficManager{}
+		r := tm.dryRunIntercept(ctx, spec(""))
+		assert.Equal(t, common.InterceptError_FAILED_TO_ESTABLISH, r.Error)
+		assert.Contains(t, r.ErrorText, "multiple interceptable service ports")
+	})
+
+	t.Run("no such workload", func(t *testing.T) {
+		tm := &TrafficManager{}
+		s := spec("http")
+		s.Agent = "nope"
+		r := tm.dryRunIntercept(ctx, s)
+		assert.Equal(t, common.InterceptError_NO_ACCEPTABLE_WORKLOAD, r.Error)
+	})
+
+	// A dry-run must not create the agents ConfigMap
+	cms, err := clientset.CoreV1().ConfigMaps("some-ns").List(ctx, meta.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, cms.Items)
+}
+
+func Test_checkManagerInstalled(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	err := checkManagerInstalled(k8sapi.WithK8sInterface(ctx, fake.NewSimpleClientset()), "ambassador")
+	assert.EqualError(t, err, `the traffic-manager is not installed in namespace ambassador. Use "telepresence connect" to install it`)
+
+	clientset := fake.NewSimpleClientset(&core.Service{
+		ObjectMeta: meta.ObjectMeta{Name: "traffic-manager", Namespace: "ambassador"},
+	})
+	assert.NoError(t, checkManagerInstalled(k8sapi.WithK8sInterface(ctx, clientset), "ambassador"))
+}
@@ -41,6 +41,7 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/forwarder"
 	"github.com/telepresenceio/telepresence/v2/pkg/install"
 	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/log"
 	"github.com/telepresenceio/telepresence/v2/pkg/matcher"
 	"github.com/telepresenceio/telepresence/v2/pkg/proc"
 	"github.com/telepresenceio/telepresence/v2/pkg/restapi"
@@ -136,6 +137,10 @@ func (tm *TrafficManager) reconcileMountPoints(ctx context.Context, existingInte
 		}
 		return true
 	})
+	if len(mountsToDelete) == 0 {
+		return
+	}
+	defer tm.saveMountPointsToUserCache(ctx)
 
 	for _, key := range mountsToDelete {
 		if _, loaded := tm.mountPoints.LoadAndDelete(key); loaded {
@@ -270,9 +275,34 @@ func (tm *TrafficManager) getCurrentIntercepts() []*manager.InterceptInfo {
 	for _, ii := range intercepts {
 		ii.ClientMountPoint = tm.mountPointForIntercept(ii.Spec.Name)
 	}
+	if tm.managerVersion.LT(firstInterceptParentVersion) {
+		inferInterceptParents(intercepts)
+	}
 	return intercepts
 }
 
+// inferInterceptParents sets the parent of each intercept that has no parent, but shares workload and
+// namespace with another intercept and is named like a companion of that intercept, i.e. "<name>-<port>"
+// where <port> is its target port. This is how companions were found before the traffic-manager retained
+// the parent, so that they can still be removed together with the intercept that they were created
+// alongside.
+func inferInterceptParents(iis []*manager.InterceptInfo) {
+	for _, ii := range iis {
+		spec := ii.Spec
+		if spec.Parent != "" || spec.Agent == "" {
+			continue
+		}
+		for _, pi := range iis {
+			ps := pi.Spec
+			if ps != spec && ps.Agent == spec.Agent && ps.Namespace == spec.Namespace &&
+				spec.Name == ps.Name+"-"+strconv.Itoa(int(spec.TargetPort)) {
+				spec.Parent = ps.Name
+				break
+			}
+		}
+	}
+}
+
 func (tm *TrafficManager) setCurrentIntercepts(ctx context.Context, intercepts []*manager.InterceptInfo) {
 	tm.currentInterceptsLock.Lock()
 	tm.currentIntercepts = intercepts
@@ -348,7 +378,12 @@ func (s *serviceProps) portIdentifier() (agentconfig.PortIdentifier, error) {
 // CanIntercept checks if it is possible to create an intercept for the given request. The intercept can proceed
 // only if the returned rpc.InterceptResult is nil. The returned runtime.Object is either nil, indicating a local
 // intercept, or the workload for the intercept.
+//
+// A dry-run request doesn't prepare the intercept. Its rpc.InterceptResult describes the intercept instead,
+// and the returned serviceProps is always nil.
 func (tm *TrafficManager) CanIntercept(c context.Context, ir *rpc.CreateInterceptRequest) (*serviceProps, *rpc.InterceptResult) {
+	c = dlog.WithField(c, log.InterceptKey, ir.Spec.Name)
+	c = withInterceptTimeouts(c, ir)
 	tm.waitForSync(c)
 	spec := ir.Spec
 	spec.Namespace = tm.ActualNamespace(spec.Namespace)
@@ -375,8 +410,17 @@ func (tm *TrafficManager) CanIntercept(c context.Context, ir *rpc.CreateIntercep
 		}
 	}
 	if spec.Agent == "" {
+		if ir.DryRun {
+			return nil, &rpc.InterceptResult{InterceptInfo: &manager.InterceptInfo{Spec: spec}}
+		}
 		return nil, nil
 	}
+	if err := resolvePortName(c, spec); err != nil {
+		return nil, interceptError(common.InterceptError_FAILED_TO_ESTABLISH, err)
+	}
+	if ir.DryRun {
+		return nil, tm.dryRunIntercept(c, spec)
+	}
 
 	apiKey, err := tm.getCloudAPIKey(c, a8rcloud.KeyDescAgent(spec), false)
 	if err != nil {
@@ -390,13 +434,21 @@ func (tm *TrafficManager) CanIntercept(c context.Context, ir *rpc.CreateIntercep
 		return tm.legacyCanInterceptEpilog(c, ir, apiKey)
 	}
 
-	pi, err := tm.managerClient.PrepareIntercept(c, &manager.CreateInterceptRequest{
+	// The traffic-manager installs the agent, if needed, before it responds. It uses a default timeout unless
+	// the request carries a deadline.
+	pc := c
+	if ir.AgentInstallTimeout != nil {
+		var cancel context.CancelFunc
+		pc, cancel = client.GetConfig(c).Timeouts.TimeoutContext(c, client.TimeoutAgentInstall)
+		defer cancel()
+	}
+	pi, err := tm.managerClient.PrepareIntercept(pc, &manager.CreateInterceptRequest{
 		Session:       tm.session(),
 		InterceptSpec: spec,
 		ApiKey:        apiKey,
 	})
 	if err != nil {
-		return nil, interceptError(common.InterceptError_TRAFFIC_MANAGER_ERROR, err)
+		return nil, interceptError(common.InterceptError_TRAFFIC_MANAGER_ERROR, client.CheckTimeout(pc, err))
 	}
 	if pi.Error != "" {
 		return nil, interceptError(common.InterceptError_TRAFFIC_MANAGER_ERROR, errcat.Category(pi.ErrorCategory).Newf(pi.Error))
@@ -486,6 +538,11 @@ func (tm *TrafficManager) legacyCanInterceptEpilog(c context.Context, ir *rpc.Cr
 
 // AddIntercept adds one intercept
 func (tm *TrafficManager) AddIntercept(c context.Context, ir *rpc.CreateInterceptRequest) (result *rpc.InterceptResult, err error) { //nolint:gocognit // bugger off
+	if ir.DryRun {
+		return interceptError(common.InterceptError_TRAFFIC_MANAGER_ERROR, errcat.User.New("a dry-run must use CanIntercept")), nil
+	}
+	c = dlog.WithField(c, log.InterceptKey, ir.Spec.Name)
+	c = withInterceptTimeouts(c, ir)
 	var svcProps *serviceProps
 	svcProps, result = tm.CanIntercept(c, ir)
 	if result != nil && result.Error != common.InterceptError_UNSPECIFIED {
@@ -545,6 +602,7 @@ func (tm *TrafficManager) AddIntercept(c context.Context, ir *rpc.CreateIntercep
 			if prev, loaded := tm.mountPoints.LoadOrStore(ir.MountPoint, spec.Name); loaded {
 				return interceptError(common.InterceptError_MOUNT_POINT_BUSY, errcat.User.Newf(prev.(string))), nil
 			}
+			tm.saveMountPointsToUserCache(c)
 
 			// Assume that the mount-point should to be removed from the busy map. Only a happy path
 			// to successful intercept that actually has remote mounts will set this to false.
@@ -552,6 +610,7 @@ func (tm *TrafficManager) AddIntercept(c context.Context, ir *rpc.CreateIntercep
 			defer func() {
 				if deleteMount {
 					tm.mountPoints.Delete(ir.MountPoint)
+					tm.saveMountPointsToUserCache(c)
 				}
 			}()
 		}
@@ -776,6 +835,7 @@ func (tm *TrafficManager) workerMountForwardIntercept(ctx context.Context, mf mo
 
 // RemoveIntercept removes one intercept by name
 func (tm *TrafficManager) RemoveIntercept(c context.Context, name string) error {
+	c = dlog.WithField(c, log.InterceptKey, name)
 	dlog.Debugf(c, "Removing intercept %s", name)
 
 	if ns, ok := tm.localIntercepts[name]; ok {
@@ -1021,3 +1081,10 @@ func (tm *TrafficManager) RemoveLocalOnlyIntercept(c context.Context, name, name
 	tm.updateDaemonNamespaces(c)
 	return nil
 }
+
+// withInterceptTimeouts returns a context where the timeouts of the given request, if any, override the
+// configured timeouts.
+func withInterceptTimeouts(c context.Context, ir *rpc.CreateInterceptRequest) context.Context {
+	c = client.WithTimeoutFlag(c, "intercept-timeout", ir.InterceptTimeout.AsDuration(), client.TimeoutIntercept)
+	return client.WithTimeoutFlag(c, "agent-install-timeout", ir.AgentInstallTimeout.AsDuration(), client.TimeoutAgentInstall)
+}
@@ -0,0 +1,58 @@
+package trafficmgr
+
+import (
+	"testing"
+
+	"github.com/blang/semver"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+)
+
+func Test_getCurrentIntercepts_parents(t *testing.T) {
+	ii := func(name, agent, namespace string, port int32) *manager.InterceptInfo {
+		return &manager.InterceptInfo{Spec: &manager.InterceptSpec{
+			Name:       name,
+			Agent:      agent,
+			Namespace:  namespace,
+			TargetPort: port,
+		}}
+	}
+	parents := func(tm *TrafficManager) map[string]string {
+		m := make(map[string]string)
+		for _, ii := range tm.getCurrentIntercepts() {
+			m[ii.Spec.Name] = ii.Spec.Parent
+		}
+		return m
+	}
+	tm := &TrafficManager{currentIntercepts: []*manager.InterceptInfo{
+		ii("echo-ns", "echo", "ns", 8080),
+		ii("echo-ns-9090", "echo", "ns", 9090),
+		ii("echo-ns-9092", "echo", "ns", 9000),    // port doesn't match the name
+		ii("echo-ns-9093", "other", "ns", 9093),   // other workload
+		ii("echo-ns-9094", "echo", "other", 9094), // other namespace
+	}}
+
+	t.Run("traffic-manager drops the parent", func(t *testing.T) {
+		tm.managerVersion = semver.MustParse("2.6.8")
+		assert.Equal(t, map[string]string{
+			"echo-ns":      "",
+			"echo-ns-9090": "echo-ns",
+			"echo-ns-9092": "",
+			"echo-ns-9093": "",
+			"echo-ns-9094": "",
+		}, parents(tm))
+	})
+
+	t.Run("traffic-manager retains the parent", func(t *testing.T) {
+		tm.managerVersion = firstInterceptParentVersion
+		for _, v := range parents(tm) {
+			assert.Empty(t, v)
+		}
+	})
+
+	// The snapshot itself is never modified
+	for _, ii := range tm.currentIntercepts {
+		assert.Empty(t, ii.Spec.Parent)
+	}
+}
@@ -0,0 +1,80 @@
+package trafficmgr
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cache"
+	"github.com/telepresenceio/telepresence/v2/pkg/proc"
+)
+
+const mountPointsFile = "mount-points.json"
+
+// saveMountPointsToUserCache records the mount points that are currently in use, so that a daemon that
+// is started after this one crashed can clean them up. The cache is removed when no mount points are in use.
+func (tm *TrafficManager) saveMountPointsToUserCache(ctx context.Context) {
+	var mountPoints []string
+	tm.mountPoints.Range(func(key, _ any) bool {
+		mountPoints = append(mountPoints, key.(string))
+		return true
+	})
+	var err error
+	if len(mountPoints) == 0 {
+		err = cache.DeleteFromUserCache(ctx, mountPointsFile)
+	} else {
+		sort.Strings(mountPoints)
+		err = cache.SaveToUserCache(ctx, mountPoints, mountPointsFile)
+	}
+	if err != nil {
+		dlog.Errorf(ctx, "failed to save mount points to user cache: %v", err)
+	}
+}
+
+// CleanupStaleMountPoints unmounts and removes the mount points that were recorded in the user cache by a
+// session that didn't end gracefully, e.g. because the daemon crashed. It must be called when the daemon
+// starts, before any session is created, because a session that replaces a lost one will reuse its mount
+// points.
+func CleanupStaleMountPoints(ctx context.Context) {
+	var mountPoints []string
+	if err := cache.LoadFromUserCache(ctx, &mountPoints, mountPointsFile); err != nil {
+		if !os.IsNotExist(err) {
+			dlog.Errorf(ctx, "failed to load mount points from user cache: %v", err)
+		}
+		return
+	}
+	for _, mountPoint := range mountPoints {
+		dlog.Infof(ctx, "Cleaning up stale file system mount %q", mountPoint)
+		unmount(ctx, mountPoint)
+		if err := os.Remove(mountPoint); err != nil && !os.IsNotExist(err) {
+			dlog.Errorf(ctx, "Failed to remove mount point %q: %v", mountPoint, err)
+		}
+	}
+	if err := cache.DeleteFromUserCache(ctx, mountPointsFile); err != nil {
+		dlog.Errorf(ctx, "failed to delete mount points from user cache: %v", err)
+	}
+}
+
+// unmount makes a best effort to unmount a FUSE file system. Errors are ignored since the mount point
+// might not be mounted at all.
+func unmount(ctx context.Context, mountPoint string) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	var cmd string
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		cmd, args = "umount", []string{mountPoint}
+	case "windows":
+		// sshfs-win mounts are drive letters that disappear with the process that created them.
+		return
+	default:
+		cmd, args = "fusermount", []string{"-uz", mountPoint}
+	}
+	c := proc.CommandContext(ctx, cmd, args...)
+	c.DisableLogging = true
+	_ = c.Run()
+}
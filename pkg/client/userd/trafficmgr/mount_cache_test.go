@@ -0,0 +1,53 @@
+package trafficmgr
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/cache"
+	"github.com/telepresenceio/telepresence/v2/pkg/filelocation"
+)
+
+func TestCleanupStaleMountPoints(t *testing.T) {
+	ctx := dlog.NewTestContext(t, false)
+	ctx = filelocation.WithUserHomeDir(ctx, t.TempDir())
+
+	mountDir := t.TempDir()
+	mp1 := filepath.Join(mountDir, "telfs-1")
+	mp2 := filepath.Join(mountDir, "telfs-2")
+	require.NoError(t, os.Mkdir(mp1, 0o700))
+	require.NoError(t, os.Mkdir(mp2, 0o700))
+
+	tm := &TrafficManager{}
+	tm.mountPoints.Store(mp1, "echo")
+	tm.mountPoints.Store(mp2, "hello")
+	tm.saveMountPointsToUserCache(ctx)
+
+	var saved []string
+	require.NoError(t, cache.LoadFromUserCache(ctx, &saved, mountPointsFile))
+	assert.Equal(t, []string{mp1, mp2}, saved)
+
+	// A graceful removal of one intercept updates the cache
+	tm.mountPoints.Delete(mp2)
+	tm.saveMountPointsToUserCache(ctx)
+	require.NoError(t, cache.LoadFromUserCache(ctx, &saved, mountPointsFile))
+	assert.Equal(t, []string{mp1}, saved)
+
+	// The next daemon cleans up what the crashed one left behind
+	CleanupStaleMountPoints(ctx)
+	assert.NoDirExists(t, mp1)
+	assert.DirExists(t, mp2)
+	err := cache.LoadFromUserCache(ctx, &saved, mountPointsFile)
+	assert.True(t, os.IsNotExist(err))
+
+	// The cache is removed when no mount points remain
+	tm.mountPoints.Delete(mp1)
+	tm.saveMountPointsToUserCache(ctx)
+	err = cache.LoadFromUserCache(ctx, &saved, mountPointsFile)
+	assert.True(t, os.IsNotExist(err))
+}
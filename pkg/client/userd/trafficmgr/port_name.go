@@ -0,0 +1,161 @@
+package trafficmgr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/datawire/dlib/dlog"
+	"github.com/telepresenceio/telepresence/rpc/v2/manager"
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+	"github.com/telepresenceio/telepresence/v2/pkg/client/errcat"
+	"github.com/telepresenceio/telepresence/v2/pkg/install"
+	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+)
+
+// resolvePortName translates a ServicePortIdentifier that names a container port in the workload's pod
+// template, rather than a service port, into an identifier for the service port that targets that
+// container port. The spec is left unchanged unless such a translation is made. An error that lists the
+// available ports is returned when the name matches neither a service port nor exactly one container port.
+func resolvePortName(c context.Context, spec *manager.InterceptSpec) error {
+	id := spec.ServicePortIdentifier
+	if id == "" || agentconfig.PortIdentifier(id).HasProto() {
+		return nil
+	}
+	if _, err := strconv.Atoi(id); err == nil {
+		return nil
+	}
+	wl, err := k8sapi.GetWorkload(c, spec.Agent, spec.Namespace, spec.WorkloadKind)
+	if err != nil {
+		// Let the traffic-manager report the problem
+		dlog.Debugf(c, "unable to resolve port name %q: %v", id, err)
+		return nil
+	}
+	podTpl := wl.GetPodTemplate()
+	svcs, err := install.FindMatchingServices(c, "", spec.ServiceName, spec.Namespace, podTpl.Labels)
+	if err != nil {
+		dlog.Debugf(c, "unable to resolve port name %q: %v", id, err)
+		return nil
+	}
+	svc, portID, err := containerPortServicePort(id, svcs, podTpl)
+	if err != nil {
+		return errcat.User.Newf("%s %s.%s: %v", strings.ToLower(wl.GetKind()), wl.GetName(), wl.GetNamespace(), err)
+	}
+	if svc != nil {
+		dlog.Debugf(c, "container port %q is targeted by port %s of service %s", id, portID, svc.Name)
+		spec.ServiceName = svc.Name
+		spec.ServicePortIdentifier = portID
+	}
+	return nil
+}
+
+// containerPortServicePort returns the service and the identifier of its port that targets the container
+// port with the given name. A nil service is returned when the name is the name of a service port.
+func containerPortServicePort(name string, svcs []*core.Service, podTpl *core.PodTemplateSpec) (*core.Service, string, error) {
+	for _, svc := range svcs {
+		for _, sp := range svc.Spec.Ports {
+			if sp.Name == name {
+				return nil, "", nil
+			}
+		}
+	}
+
+	var cp *core.ContainerPort
+	for ci := range podTpl.Spec.Containers {
+		cn := &podTpl.Spec.Containers[ci]
+		if cn.Name == agentconfig.ContainerName {
+			continue
+		}
+		for pi := range cn.Ports {
+			if cn.Ports[pi].Name == name {
+				if cp != nil {
+					return nil, "", fmt.Errorf("port name %q is ambiguous, it's used by more than one container; %s",
+						name, availablePorts(svcs, podTpl))
+				}
+				cp = &cn.Ports[pi]
+			}
+		}
+	}
+	if cp == nil {
+		return nil, "", fmt.Errorf("no service port or container port is named %q; %s", name, availablePorts(svcs, podTpl))
+	}
+
+	var matchSvc *core.Service
+	var matchPort *core.ServicePort
+	for _, svc := range svcs {
+		for pi := range svc.Spec.Ports {
+			sp := &svc.Spec.Ports[pi]
+			if sp.Protocol != "" && cp.Protocol != "" && sp.Protocol != cp.Protocol {
+				continue
+			}
+			tp := sp.TargetPort
+			if tp.Type == intstr.String && tp.StrVal == cp.Name || tp.Type == intstr.Int && targetPortNumber(sp) == cp.ContainerPort {
+				if matchPort != nil {
+					return nil, "", fmt.Errorf("container port %q is targeted by more than one service port; %s",
+						name, availablePorts(svcs, podTpl))
+				}
+				matchSvc, matchPort = svc, sp
+			}
+		}
+	}
+	if matchPort == nil {
+		return nil, "", fmt.Errorf("container port %q is not targeted by any service port; %s", name, availablePorts(svcs, podTpl))
+	}
+	if matchPort.Name != "" {
+		return matchSvc, matchPort.Name, nil
+	}
+	// An unnamed service port is identified by its port number, not by the number of the container port it targets
+	return matchSvc, strconv.Itoa(int(matchPort.Port)), nil
+}
+
+// targetPortNumber returns the number of the container port that the given service port targets, which
+// is the port itself when no numeric target port is given.
+func targetPortNumber(sp *core.ServicePort) int32 {
+	if sp.TargetPort.Type == intstr.Int && sp.TargetPort.IntVal != 0 {
+		return sp.TargetPort.IntVal
+	}
+	return sp.Port
+}
+
+// availablePorts returns a description of the service ports and container ports that can be used to
+// identify the port to intercept.
+func availablePorts(svcs []*core.Service, podTpl *core.PodTemplateSpec) string {
+	var ports []string
+	add := func(name string, number int32) {
+		if name == "" {
+			ports = append(ports, strconv.Itoa(int(number)))
+		} else {
+			ports = append(ports, fmt.Sprintf("%s (%d)", name, number))
+		}
+	}
+	for _, svc := range svcs {
+		for pi := range svc.Spec.Ports {
+			sp := &svc.Spec.Ports[pi]
+			add(sp.Name, sp.Port)
+		}
+	}
+	for _, cn := range podTpl.Spec.Containers {
+		if cn.Name == agentconfig.ContainerName {
+			continue
+		}
+		for _, cp := range cn.Ports {
+			add(cp.Name, cp.ContainerPort)
+		}
+	}
+	if len(ports) == 0 {
+		return "the workload has no ports"
+	}
+	sort.Strings(ports)
+	unique := ports[:1]
+	for _, p := range ports[1:] {
+		if p != unique[len(unique)-1] {
+			unique = append(unique, p)
+		}
+	}
+	return "available ports are: " + strings.Join(unique, ", ")
+}
@@ -0,0 +1,76 @@
+package trafficmgr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/telepresenceio/telepresence/v2/pkg/agentconfig"
+)
+
+func Test_containerPortServicePort(t *testing.T) {
+	svc := &core.Service{
+		ObjectMeta: meta.ObjectMeta{Name: "echo"},
+		Spec: core.ServiceSpec{Ports: []core.ServicePort{
+			{Name: "web", Port: 80, TargetPort: intstr.FromString("http")},
+			{Port: 9000, TargetPort: intstr.FromInt(9090)},
+			{Name: "metrics", Port: 9100},
+		}},
+	}
+	podTpl := &core.PodTemplateSpec{Spec: core.PodSpec{Containers: []core.Container{
+		{Name: "echo", Ports: []core.ContainerPort{
+			{Name: "http", ContainerPort: 8080},
+			{Name: "grpc", ContainerPort: 9090},
+			{Name: "debug", ContainerPort: 5005},
+		}},
+		{Name: agentconfig.ContainerName, Ports: []core.ContainerPort{{Name: "tm-http", ContainerPort: 9900}}},
+	}}}
+	svcs := []*core.Service{svc}
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "web"},                // service port name, no translation
+		{name: "http", want: "web"},  // targeted by named service port
+		{name: "grpc", want: "9000"}, // targeted by unnamed service port
+		{name: "debug", wantErr: `container port "debug" is not targeted by any service port`},
+		{name: "tm-http", wantErr: `no service port or container port is named "tm-http"`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s, id, err := containerPortServicePort(tt.name, svcs, podTpl)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Contains(t, err.Error(),
+					"available ports are: 9000, debug (5005), grpc (9090), http (8080), metrics (9100), web (80)")
+				return
+			}
+			require.NoError(t, err)
+			if tt.want == "" {
+				assert.Nil(t, s)
+			} else {
+				assert.Equal(t, svc, s)
+				assert.Equal(t, tt.want, id)
+			}
+		})
+	}
+
+	t.Run("ambiguous", func(t *testing.T) {
+		podTpl := podTpl.DeepCopy()
+		podTpl.Spec.Containers = append(podTpl.Spec.Containers, core.Container{
+			Name:  "sidecar",
+			Ports: []core.ContainerPort{{Name: "http", ContainerPort: 8081}},
+		})
+		_, _, err := containerPortServicePort("http", svcs, podTpl)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `port name "http" is ambiguous`)
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 	empty "google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	core "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -48,6 +49,7 @@ import (
 	"github.com/telepresenceio/telepresence/v2/pkg/install/helm"
 	"github.com/telepresenceio/telepresence/v2/pkg/iputil"
 	"github.com/telepresenceio/telepresence/v2/pkg/k8sapi"
+	"github.com/telepresenceio/telepresence/v2/pkg/log"
 	"github.com/telepresenceio/telepresence/v2/pkg/matcher"
 	"github.com/telepresenceio/telepresence/v2/pkg/restapi"
 )
@@ -182,6 +184,20 @@ type TrafficManager struct {
 	sr              *scout.Reporter
 
 	isPodDaemon bool
+
+	// demo is true when the session uses an in-process fake cluster and traffic-manager
+	demo bool
+
+	// sessionExpiry is the time when the session ends, or zero if the session has no time limit
+	sessionExpiry time.Time
+
+	// noReconnect is true when a lost connection to the traffic-manager must not end the session
+	noReconnect bool
+
+	// dnsSearch and dnsResolvers are the DNS search domains and upstream resolvers given to the connect
+	// command. They are added to those of the kubeconfig extension.
+	dnsSearch    []string
+	dnsResolvers map[string]string
 }
 
 // interceptResult is what gets written to the activeInterceptsWaiters channels
@@ -194,9 +210,15 @@ type interceptResult struct {
 // TODO: Change to released version
 var firstAgentConfigMapVersion = semver.MustParse("2.6.0-alpha.64")
 
+// firstInterceptParentVersion first version of traffic-manager that retains the parent of an intercept spec
+var firstInterceptParentVersion = semver.MustParse("2.7.0")
+
 func NewSession(c context.Context, sr *scout.Reporter, cr *rpc.ConnectRequest, svc Service, extraServices []SessionService) (context.Context, Session, *connector.ConnectInfo) {
 	dlog.Info(c, "-- Starting new session")
 	sr.Report(c, "connect")
+	if cr.Demo {
+		return newDemoSession(c, sr, cr, svc, extraServices)
+	}
 
 	var rootDaemon daemon.DaemonClient
 	if !cr.IsPodDaemon {
@@ -208,7 +230,7 @@ func NewSession(c context.Context, sr *scout.Reporter, cr *rpc.ConnectRequest, s
 	}
 
 	dlog.Info(c, "Connecting to k8s cluster...")
-	cluster, err := connectCluster(c, cr)
+	cluster, err := connectCluster(withConnectTimeout(c, cr), cr)
 	if err != nil {
 		dlog.Errorf(c, "unable to track k8s cluster: %+v", err)
 		return c, nil, connectError(rpc.ConnectInfo_CLUSTER_FAILED, err)
@@ -228,7 +250,7 @@ func NewSession(c context.Context, sr *scout.Reporter, cr *rpc.ConnectRequest, s
 	connectStart := time.Now()
 
 	dlog.Info(c, "Connecting to traffic manager...")
-	tmgr, err := connectMgr(c, cluster, sr.InstallID(), svc, rootDaemon, cr.IsPodDaemon)
+	tmgr, err := connectMgr(withConnectTimeout(c, cr), cluster, sr.InstallID(), svc, rootDaemon, cr.IsPodDaemon, cr.NoInstall)
 
 	if err != nil {
 		dlog.Errorf(c, "Unable to connect to TrafficManager: %s", err)
@@ -237,6 +259,13 @@ func NewSession(c context.Context, sr *scout.Reporter, cr *rpc.ConnectRequest, s
 
 	tmgr.sessionServices = extraServices
 	tmgr.sr = sr
+	if sd := cr.SessionDuration; sd != nil && sd.AsDuration() > 0 {
+		tmgr.sessionExpiry = time.Now().Add(sd.AsDuration())
+		dlog.Infof(c, "Session expires at %s", tmgr.sessionExpiry.Format(time.RFC3339))
+	}
+	tmgr.noReconnect = cr.NoReconnect
+	tmgr.dnsSearch = cr.DnsSearch
+	tmgr.dnsResolvers = cr.DnsResolvers
 
 	// Must call SetManagerClient before calling daemon.Connect which tells the
 	// daemon to use the proxy.
@@ -296,6 +325,8 @@ func NewSession(c context.Context, sr *scout.Reporter, cr *rpc.ConnectRequest, s
 		SessionInfo:    tmgr.session(),
 		Intercepts:     &manager.InterceptInfoSnapshot{Intercepts: tmgr.getCurrentIntercepts()},
 	}
+	tmgr.setExpiryStatus(ret)
+	c = dlog.WithField(c, log.SessionIDKey, ret.SessionInfo.GetSessionId())
 	c = WithSession(c, tmgr)
 	return c, tmgr, ret
 }
@@ -323,6 +354,13 @@ func (tm *TrafficManager) ManagerConn() *grpc.ClientConn {
 	return tm.managerConn
 }
 
+// withConnectTimeout returns a context where the connect timeout of the given request, if any, overrides the
+// configured timeouts of all phases of the connect.
+func withConnectTimeout(c context.Context, cr *rpc.ConnectRequest) context.Context {
+	return client.WithTimeoutFlag(c, "connect-timeout", cr.ConnectTimeout.AsDuration(),
+		client.TimeoutClusterConnect, client.TimeoutHelm, client.TimeoutTrafficManagerConnect)
+}
+
 // connectCluster returns a configured cluster instance
 func connectCluster(c context.Context, cr *rpc.ConnectRequest) (*k8s.Cluster, error) {
 	var config *k8s.Config
@@ -351,8 +389,9 @@ func connectCluster(c context.Context, cr *rpc.ConnectRequest) (*k8s.Cluster, er
 	return cluster, nil
 }
 
-// connectMgr returns a session for the given cluster that is connected to the traffic-manager.
-func connectMgr(c context.Context, cluster *k8s.Cluster, installID string, svc Service, rootDaemon daemon.DaemonClient, isPodDaemon bool) (*TrafficManager, error) {
+// connectMgr returns a session for the given cluster that is connected to the traffic-manager. The traffic-manager
+// is installed unless noInstall is true, in which case an error is returned when it isn't installed.
+func connectMgr(c context.Context, cluster *k8s.Cluster, installID string, svc Service, rootDaemon daemon.DaemonClient, isPodDaemon, noInstall bool) (*TrafficManager, error) {
 	clientConfig := client.GetConfig(c)
 	tos := &clientConfig.Timeouts
 
@@ -379,10 +418,17 @@ func connectMgr(c context.Context, cluster *k8s.Cluster, installID string, svc S
 		return nil, stacktrace.Wrap(err, "new installer")
 	}
 
-	dlog.Debug(c, "ensure that traffic-manager exists")
-	if err = ti.EnsureManager(c); err != nil {
-		dlog.Errorf(c, "failed to ensure traffic-manager, %v", err)
-		return nil, fmt.Errorf("failed to ensure traffic manager: %w", err)
+	if noInstall {
+		dlog.Debug(c, "check that traffic-manager exists")
+		if err = checkManagerInstalled(c, cluster.GetManagerNamespace()); err != nil {
+			return nil, err
+		}
+	} else {
+		dlog.Debug(c, "ensure that traffic-manager exists")
+		if err = ti.EnsureManager(c); err != nil {
+			dlog.Errorf(c, "failed to ensure traffic-manager, %v", err)
+			return nil, fmt.Errorf("failed to ensure traffic manager: %w", err)
+		}
 	}
 
 	dlog.Debug(c, "traffic-manager started, creating port-forward")
@@ -489,6 +535,21 @@ func connectMgr(c context.Context, cluster *k8s.Cluster, installID string, svc S
 	}, nil
 }
 
+// checkManagerInstalled returns an errcat.User error when the traffic-manager service doesn't exist in the
+// given namespace.
+func checkManagerInstalled(c context.Context, namespace string) error {
+	_, err := k8sapi.GetK8sInterface(c).CoreV1().Services(namespace).Get(c, install.ManagerAppName, meta.GetOptions{})
+	switch {
+	case err == nil:
+		return nil
+	case k8serrors.IsNotFound(err):
+		return errcat.User.Newf("the traffic-manager is not installed in namespace %s. "+
+			"Use \"telepresence connect\" to install it", namespace)
+	default:
+		return fmt.Errorf("unable to get service %s.%s: %w", install.ManagerAppName, namespace, err)
+	}
+}
+
 func connectError(t rpc.ConnectInfo_ErrType, err error) *rpc.ConnectInfo {
 	return &rpc.ConnectInfo{
 		Error:         t,
@@ -526,6 +587,10 @@ func (tm *TrafficManager) updateDaemonNamespaces(c context.Context) {
 	// Pass current mapped namespaces as plain names (no ending dot). The DNS-resolver will
 	// create special mapping for those, allowing names like myservice.mynamespace to be resolved
 	paths := tm.GetCurrentNamespaces(false)
+	if tm.rootDaemon == nil {
+		// Demo sessions have no root daemon
+		return
+	}
 	dlog.Debugf(c, "posting search paths %v and namespaces %v", paths, namespaces)
 	if _, err := tm.rootDaemon.SetDnsSearchPath(c, &daemon.Paths{Paths: paths, Namespaces: namespaces}); err != nil {
 		dlog.Errorf(c, "error posting search paths %v and namespaces %v to root daemon: %v", paths, namespaces, err)
@@ -548,6 +613,12 @@ func (tm *TrafficManager) Run(c context.Context) error {
 	g.Go("intercept-port-forward", tm.workerPortForwardIntercepts)
 	g.Go("agent-watcher", tm.agentInfoWatcher)
 	g.Go("dial-request-watcher", tm.dialRequestWatcher)
+	if tr := tm.TokenRefresher(); tr != nil {
+		g.Go("token-refresher", tr.Run)
+	}
+	if !tm.sessionExpiry.IsZero() {
+		g.Go("session-timer", tm.sessionTimer)
+	}
 	for _, svc := range tm.sessionServices {
 		func(svc SessionService) {
 			dlog.Infof(c, "Starting additional session service %s", svc.Name())
@@ -790,6 +861,29 @@ nextIs:
 
 var SessionExpiredErr = errors.New("session expired")
 
+// ConnectionLostErr is returned from Run when the traffic-manager has been unreachable for
+// connectionLostAttempts consecutive calls to Remain, unless the session was created with no_reconnect.
+var ConnectionLostErr = errors.New("connection to the traffic-manager lost")
+
+// connectionLostAttempts is the number of consecutive failed calls to Remain after which the connection
+// to the traffic-manager is considered lost.
+const connectionLostAttempts = 3
+
+// SessionDurationElapsedErr is returned from Run when the session duration given in the connect request has elapsed.
+var SessionDurationElapsedErr = errors.New("session duration elapsed")
+
+func (tm *TrafficManager) sessionTimer(c context.Context) error {
+	tmr := time.NewTimer(time.Until(tm.sessionExpiry))
+	defer tmr.Stop()
+	select {
+	case <-c.Done():
+		return nil
+	case <-tmr.C:
+		dlog.Infof(c, "Session duration elapsed at %s", tm.sessionExpiry.Format(time.RFC3339))
+		return SessionDurationElapsedErr
+	}
+}
+
 func (tm *TrafficManager) remain(c context.Context) error {
 	ticker := time.NewTicker(5 * time.Second)
 	defer func() {
@@ -808,32 +902,61 @@ func (tm *TrafficManager) remain(c context.Context) error {
 		tm.managerConn.Close()
 	}()
 
+	failures := 0
 	for {
 		select {
 		case <-c.Done():
 			return nil
 		case <-ticker.C:
-			_, err := tm.managerClient.Remain(c, &manager.RemainRequest{
-				Session: tm.session(),
-				ApiKey: func() string {
-					// Discard any errors; including an apikey with this request
-					// is optional.  We might not even be logged in.
-					tok, _ := tm.getCloudAPIKey(c, a8rcloud.KeyDescTrafficManager, false)
-					return tok
-				}(),
-			})
-			if err != nil && c.Err() == nil {
-				dlog.Error(c, err)
-				if gErr, ok := status.FromError(err); ok && gErr.Code() == codes.NotFound {
-					// Session has expired. We need to cancel the owner session and reconnect
-					return SessionExpiredErr
-				}
+			err := tm.remainOnce(c)
+			if err == nil || c.Err() != nil {
+				failures = 0
+				continue
+			}
+			dlog.Error(c, err)
+			gErr, ok := status.FromError(err)
+			if ok && gErr.Code() == codes.NotFound {
+				// Session has expired. We need to cancel the owner session and reconnect
+				return SessionExpiredErr
+			}
+			failures++
+			if failures >= connectionLostAttempts && !tm.noReconnect {
+				return ConnectionLostErr
 			}
 		}
 	}
 }
 
+func (tm *TrafficManager) remainOnce(c context.Context) error {
+	// A connection that is lost without being closed, e.g. when the laptop sleeps, must not block the call forever.
+	c, cancel := client.GetConfig(c).Timeouts.TimeoutContext(c, client.TimeoutTrafficManagerAPI)
+	defer cancel()
+	_, err := tm.managerClient.Remain(c, &manager.RemainRequest{
+		Session: tm.session(),
+		ApiKey: func() string {
+			// Discard any errors; including an apikey with this request
+			// is optional.  We might not even be logged in.
+			tok, _ := tm.getCloudAPIKey(c, a8rcloud.KeyDescTrafficManager, false)
+			return tok
+		}(),
+	})
+	return err
+}
+
 func (tm *TrafficManager) UpdateStatus(c context.Context, cr *rpc.ConnectRequest) *rpc.ConnectInfo {
+	if tm.demo || cr.Demo {
+		// A demo session can't be reconfigured, and it can't be replaced without a restart.
+		if !(tm.demo && cr.Demo) {
+			return &rpc.ConnectInfo{
+				Error:          rpc.ConnectInfo_MUST_RESTART,
+				ClusterContext: tm.Config.Context,
+				ClusterServer:  tm.Config.Server,
+				ClusterId:      tm.GetClusterId(c),
+			}
+		}
+		return tm.Status(c)
+	}
+
 	var config *k8s.Config
 	var err error
 	if cr.IsPodDaemon {
@@ -872,9 +995,23 @@ func (tm *TrafficManager) Status(c context.Context) *rpc.ConnectInfo {
 		SessionInfo:    tm.session(),
 		Intercepts:     &manager.InterceptInfoSnapshot{Intercepts: tm.getCurrentIntercepts()},
 	}
+	tm.setExpiryStatus(ret)
 	return ret
 }
 
+// setExpiryStatus sets the expiry times of the session and its cluster token in the given ConnectInfo.
+func (tm *TrafficManager) setExpiryStatus(ci *rpc.ConnectInfo) {
+	if tr := tm.TokenRefresher(); tr != nil {
+		ci.TokenExpiry = timestamppb.New(tr.Expiry())
+		if err := tr.LastError(); err != nil {
+			ci.TokenRefreshError = err.Error()
+		}
+	}
+	if !tm.sessionExpiry.IsZero() {
+		ci.SessionExpiry = timestamppb.New(tm.sessionExpiry)
+	}
+}
+
 // Given a slice of AgentInfo, this returns another slice of agents with one
 // agent per namespace, name pair.
 // Deprecated: not used with traffic-manager versions >= 2.6.0
@@ -967,6 +1104,9 @@ func (tm *TrafficManager) Uninstall(ctx context.Context, ur *rpc.UninstallReques
 		}
 		return r, nil
 	}
+	if tm.demo {
+		return result(errcat.User.New("there's nothing to uninstall in a demo session"))
+	}
 
 	if ur.UninstallType == rpc.UninstallRequest_EVERYTHING {
 		_ = tm.ClearIntercepts(ctx)
@@ -1116,6 +1256,11 @@ func (tm *TrafficManager) getOutboundInfo(ctx context.Context) *daemon.OutboundI
 	for _, np := range tm.NeverProxy {
 		neverProxy = append(neverProxy, iputil.IPNetToRPC((*net.IPNet)(np)))
 	}
+	if cfg := client.GetConfig(ctx); cfg != nil {
+		for _, np := range cfg.Cluster.NeverProxy {
+			neverProxy = append(neverProxy, iputil.IPNetToRPC((*net.IPNet)(np)))
+		}
+	}
 	info := &daemon.OutboundInfo{
 		Session:           tm.sessionInfo,
 		NeverProxySubnets: neverProxy,
@@ -1133,6 +1278,25 @@ func (tm *TrafficManager) getOutboundInfo(ctx context.Context) *daemon.OutboundI
 		if len(tm.DNS.RemoteIP) > 0 {
 			info.Dns.RemoteIp = tm.DNS.RemoteIP.IP()
 		}
+		info.Dns.SearchDomains = tm.DNS.Search
+		info.Dns.Resolvers = tm.DNS.Resolvers
+	}
+	if len(tm.dnsSearch) > 0 || len(tm.dnsResolvers) > 0 {
+		if info.Dns == nil {
+			info.Dns = &daemon.DNSConfig{}
+		}
+		info.Dns.SearchDomains = append(info.Dns.SearchDomains, tm.dnsSearch...)
+		if len(tm.dnsResolvers) > 0 {
+			// Resolvers given to the connect command take precedence over those of the kubeconfig extension.
+			resolvers := make(map[string]string, len(info.Dns.Resolvers)+len(tm.dnsResolvers))
+			for domain, addr := range info.Dns.Resolvers {
+				resolvers[domain] = addr
+			}
+			for domain, addr := range tm.dnsResolvers {
+				resolvers[domain] = addr
+			}
+			info.Dns.Resolvers = resolvers
+		}
 	}
 
 	if len(tm.AlsoProxy) > 0 {
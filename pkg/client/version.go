@@ -17,6 +17,11 @@ func Version() string {
 	return version.Version
 }
 
+// VersionSource returns where the version of this executable was obtained from. See version.Source.
+func VersionSource() string {
+	return version.Source
+}
+
 func Semver() semver.Version {
 	return version.Structured()
 }
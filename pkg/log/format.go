@@ -0,0 +1,56 @@
+package log
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// The supported log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+type setLogFormatContextKey struct{}
+
+// ValidateFormat returns an error unless the given format is FormatText or FormatJSON.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unsupported log format %q, supported formats are %q and %q", format, FormatText, FormatJSON)
+	}
+}
+
+// SetFormat sets the log format for the logger of the given context
+func SetFormat(ctx context.Context, format string) {
+	if setter, ok := ctx.Value(setLogFormatContextKey{}).(func(string)); ok {
+		setter(format)
+	}
+}
+
+// WithFormatSetter enables setting the format of the given Logger by using the returned context as an
+// argument to the SetFormat function. The component is used by the JSON format and the timestampFormat
+// by the text format.
+func WithFormatSetter(ctx context.Context, logrusLogger *logrus.Logger, component, timestampFormat string) context.Context {
+	return context.WithValue(ctx, setLogFormatContextKey{}, func(format string) {
+		SetLogrusFormat(logrusLogger, format, component, timestampFormat)
+	})
+}
+
+// SetLogrusFormat sets the formatter of the given logger. An unsupported format is logged and the
+// formatter is left unchanged.
+func SetLogrusFormat(logrusLogger *logrus.Logger, format, component, timestampFormat string) {
+	if err := ValidateFormat(format); err != nil {
+		logrusLogger.Error(err)
+		return
+	}
+	if format == FormatJSON {
+		logrusLogger.SetFormatter(NewJSONFormatter(component))
+	} else {
+		logrusLogger.SetFormatter(NewFormatter(timestampFormat))
+	}
+}
@@ -35,6 +35,11 @@ func (f *Formatter) Format(entry *logrus.Entry) ([]byte, error) {
 	goroutine, _ := data["THREAD"].(string)
 	delete(data, "THREAD")
 
+	// The structured keys are only of interest to the JSON format
+	delete(data, ComponentKey)
+	delete(data, SessionIDKey)
+	delete(data, InterceptKey)
+
 	if len(goroutine) > 0 {
 		fmt.Fprintf(b, "%s %-*s %s : %s",
 			entry.Time.Format(f.timestampFormat),
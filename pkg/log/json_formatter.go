@@ -0,0 +1,68 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Keys of the fields that all structured log entries carry when they are known. The text format of the
+// Formatter omits them.
+const (
+	ComponentKey = "component"
+	SessionIDKey = "session_id"
+	InterceptKey = "intercept"
+)
+
+// JSONFormatter formats log messages for Telepresence as one JSON object per line. Each object has the
+// keys "time", "level", "component", and "msg", the keys SessionIDKey, InterceptKey, "thread", and
+// "caller" when they are known, and one key per additional field of the entry.
+type JSONFormatter struct {
+	component string
+}
+
+// NewJSONFormatter returns a formatter that writes JSON entries for the given component, e.g. "daemon".
+func NewJSONFormatter(component string) *JSONFormatter {
+	return &JSONFormatter{component: component}
+}
+
+// Format implements logrus.Formatter
+func (f *JSONFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var b *bytes.Buffer
+	if entry.Buffer != nil {
+		b = entry.Buffer
+	} else {
+		b = &bytes.Buffer{}
+	}
+	data := make(map[string]any, len(entry.Data)+6)
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		data[k] = v
+	}
+	if goroutine, ok := data["THREAD"].(string); ok {
+		delete(data, "THREAD")
+		data["thread"] = strings.TrimPrefix(goroutine, "/")
+	}
+	if _, ok := data[ComponentKey]; !ok {
+		data[ComponentKey] = f.component
+	}
+	data["time"] = entry.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+	data["level"] = entry.Level.String()
+	data["msg"] = entry.Message
+	if entry.HasCaller() && strings.HasPrefix(entry.Caller.File, thisModule+"/") {
+		data["caller"] = fmt.Sprintf("%s:%d", strings.TrimPrefix(entry.Caller.File, thisModule+"/"), entry.Caller.Line)
+	}
+
+	// The encoder sorts the keys, so entries with the same fields always have the same layout
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		return nil, fmt.Errorf("failed to marshal log entry to JSON: %w", err)
+	}
+	return b.Bytes(), nil
+}
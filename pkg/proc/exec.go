@@ -89,3 +89,10 @@ func StartInBackgroundAsRoot(ctx context.Context, args ...string) error {
 func IsAdmin() bool {
 	return isAdmin()
 }
+
+// TerminateAll terminates all processes that run the telepresence sub-command given by subCommand. The
+// processes are terminated using elevated privileges when asRoot is true. It is not an error if no such
+// process is found.
+func TerminateAll(ctx context.Context, asRoot bool, subCommand string) error {
+	return terminateAll(ctx, asRoot, subCommand)
+}
@@ -5,8 +5,10 @@ package proc
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 
 	//nolint:depguard // Because startInBackground{,AsRoot}() won't ever .Wait() for the process
 	// and we'd turn off logging, using dexec would just be extra overhead.
@@ -78,3 +80,52 @@ func startInBackgroundAsRoot(ctx context.Context, args ...string) error {
 
 	return startInBackground(args...)
 }
+
+// terminateAllArgs returns the pgrep arguments that find, and the pkill arguments that terminate, the processes
+// that run the given telepresence sub-command. Processes that don't run as root when asRoot is true, or as the
+// user with the given uid otherwise, are never matched, so that the daemons of other users on the same host are
+// left alone.
+func terminateAllArgs(asRoot bool, subCommand string, uid int) (findArgs, killArgs []string) {
+	// Enclosing the first character in brackets prevents the pattern from matching the command
+	// line of a "sudo pkill" process.
+	pattern := " [" + subCommand[:1] + "]" + subCommand[1:]
+	if asRoot {
+		uid = 0
+	}
+	user := strconv.Itoa(uid)
+	findArgs = []string{"pgrep", "-u", user, "-f", pattern}
+	killArgs = []string{"pkill", "-TERM", "-u", user, "-f", pattern}
+	if asRoot && uid != os.Geteuid() {
+		killArgs = append([]string{"sudo"}, killArgs...)
+	}
+	return findArgs, killArgs
+}
+
+func terminateAll(ctx context.Context, asRoot bool, subCommand string) error {
+	findArgs, args := terminateAllArgs(asRoot, subCommand, os.Geteuid())
+	findCmd := dexec.CommandContext(ctx, findArgs[0], findArgs[1:]...)
+	findCmd.DisableLogging = true
+	if err := findCmd.Run(); err != nil {
+		var ex *dexec.ExitError
+		if errors.As(err, &ex) && ex.ExitCode() == 1 {
+			// No matching process
+			return nil
+		}
+		return fmt.Errorf("%s: %w", shellquote.ShellString(findArgs[0], findArgs[1:]), err)
+	}
+
+	killCmd := dexec.CommandContext(ctx, args[0], args[1:]...)
+	killCmd.DisableLogging = true
+	killCmd.Stdin = os.Stdin
+	killCmd.Stdout = os.Stdout
+	killCmd.Stderr = os.Stderr
+	if err := killCmd.Run(); err != nil {
+		var ex *dexec.ExitError
+		if errors.As(err, &ex) && ex.ExitCode() == 1 {
+			// The process terminated before it could be signalled
+			return nil
+		}
+		return fmt.Errorf("%s: %w", shellquote.ShellString(args[0], args[1:]), err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+// +build !windows
+
+package proc
+
+import (
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_terminateAllArgs(t *testing.T) {
+	t.Run("user daemon", func(t *testing.T) {
+		find, kill := terminateAllArgs(false, "connector-foreground", 1234)
+		assert.Equal(t, []string{"pgrep", "-u", "1234", "-f", " [c]onnector-foreground"}, find)
+		assert.Equal(t, []string{"pkill", "-TERM", "-u", "1234", "-f", " [c]onnector-foreground"}, kill)
+	})
+
+	t.Run("root daemon", func(t *testing.T) {
+		find, kill := terminateAllArgs(true, "daemon-foreground", 1234)
+		assert.Equal(t, []string{"pgrep", "-u", "0", "-f", " [d]aemon-foreground"}, find)
+		want := []string{"pkill", "-TERM", "-u", "0", "-f", " [d]aemon-foreground"}
+		if os.Geteuid() != 0 {
+			want = append([]string{"sudo"}, want...)
+		}
+		assert.Equal(t, want, kill)
+	})
+
+	t.Run("current user", func(t *testing.T) {
+		find, _ := terminateAllArgs(false, "connector-foreground", os.Geteuid())
+		assert.Equal(t, strconv.Itoa(os.Geteuid()), find[2])
+	})
+}
@@ -2,6 +2,7 @@ package proc
 
 import (
 	"context"
+	"fmt"
 	"os"
 
 	"golang.org/x/sys/windows"
@@ -62,3 +63,24 @@ func isAdmin() bool {
 	adm, err := windows.GetCurrentProcessToken().IsMember(sid)
 	return err == nil && adm
 }
+
+func terminateAll(ctx context.Context, asRoot bool, subCommand string) error {
+	// Enclosing the first character in brackets prevents the WQL pattern from matching the command
+	// line of the powershell process itself.
+	script := fmt.Sprintf(`Get-CimInstance Win32_Process -Filter "CommandLine LIKE '%% [%s]%s%%'"`, subCommand[:1], subCommand[1:])
+	if !asRoot {
+		// Leave the processes of other users alone
+		script += ` | Where-Object { (Invoke-CimMethod -InputObject $_ -MethodName GetOwner).User -eq $env:USERNAME }`
+	}
+	script += ` | Invoke-CimMethod -MethodName Terminate | Out-Null`
+	args := []string{"powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script}
+	if asRoot && !isAdmin() {
+		return startInBackgroundAsRoot(ctx, args...)
+	}
+	cmd := CommandContext(ctx, args[0], args[1:]...)
+	cmd.DisableLogging = true
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", shellquote.ShellString(args[0], args[1:]), err)
+	}
+	return nil
+}
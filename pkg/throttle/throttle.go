@@ -0,0 +1,142 @@
+// Package throttle paces a batch of operations, such as agent injections that each trigger a rolling
+// restart, so that the cluster isn't overloaded by all of them happening at once.
+package throttle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config controls how a batch is throttled.
+type Config struct {
+	// Concurrency is the maximum number of operations that may run concurrently. A value <= 0 means
+	// that there's no limit.
+	Concurrency int
+
+	// Rate is the maximum number of operations that may be started per second. A value <= 0 means
+	// that there's no limit.
+	Rate float64
+}
+
+// Progress is called each time an operation completes. The done count includes the completed operation.
+// Calls are serialized, so a Progress function doesn't need to be thread safe.
+type Progress[T any] func(done, total int, item T, err error)
+
+// ParseRate parses a rate on the form "<number>/s" or "<number>/m", meaning operations per second or per
+// minute. A plain number is interpreted as operations per second. The returned value is always in
+// operations per second. An empty string or zero means that there's no limit.
+func ParseRate(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	ns, unit := s, time.Second
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		ns = s[:i]
+		switch s[i+1:] {
+		case "s":
+		case "m":
+			unit = time.Minute
+		default:
+			return 0, fmt.Errorf("invalid rate %q, unit must be /s or /m", s)
+		}
+	}
+	n, err := strconv.ParseFloat(ns, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid rate %q, must be a non-negative number followed by /s or /m", s)
+	}
+	return n / unit.Seconds(), nil
+}
+
+// Run calls fn once for each of the given items while respecting the limits of the given Config. The
+// items are started in the order that they are given. The progress function, if not nil, is called each
+// time a call to fn returns.
+//
+// Run returns when all started operations have completed. No new operations are started once the context
+// is cancelled. The returned error, if any, contains the errors of all failed operations.
+func Run[T any](ctx context.Context, cfg Config, items []T, fn func(context.Context, T) error, progress Progress[T]) error {
+	total := len(items)
+	concurrency := cfg.Concurrency
+	if concurrency <= 0 || concurrency > total {
+		concurrency = total
+	}
+	var interval time.Duration
+	if cfg.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / cfg.Rate)
+	}
+
+	var errs []error
+	var mu sync.Mutex
+	done := 0
+	complete := func(item T, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if progress != nil {
+			progress(done, total, item, err)
+		}
+	}
+
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	var nextStart time.Time
+	var ctxErr error
+	started := 0
+	for _, item := range items {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+		}
+		if ctxErr = ctx.Err(); ctxErr != nil {
+			break
+		}
+		if wait := time.Until(nextStart); wait > 0 {
+			tm := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				tm.Stop()
+			case <-tm.C:
+			}
+			if ctxErr = ctx.Err(); ctxErr != nil {
+				break
+			}
+		}
+		nextStart = time.Now().Add(interval)
+
+		started++
+		wg.Add(1)
+		go func(item T) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			complete(item, fn(ctx, item))
+		}(item)
+	}
+	wg.Wait()
+
+	if ctxErr != nil {
+		errs = append(errs, fmt.Errorf("%d of %d operations were not started: %w", total-started, total, ctxErr))
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		bld := bytes.NewBufferString("multiple errors:")
+		for _, err := range errs {
+			bld.WriteString("\n  ")
+			bld.WriteString(err.Error())
+		}
+		return errors.New(bld.String())
+	}
+}
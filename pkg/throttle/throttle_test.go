@@ -0,0 +1,124 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "0", want: 0},
+		{in: "5", want: 5},
+		{in: "5/s", want: 5},
+		{in: "0.5/s", want: 0.5},
+		{in: "30/m", want: 0.5},
+		{in: "5/h", wantErr: true},
+		{in: "-1/s", wantErr: true},
+		{in: "fast", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseRate(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func items(n int) []int {
+	is := make([]int, n)
+	for i := range is {
+		is[i] = i
+	}
+	return is
+}
+
+func TestRun_concurrency(t *testing.T) {
+	const limit = 3
+	var current, maxSeen int32
+	err := Run(context.Background(), Config{Concurrency: limit}, items(12), func(_ context.Context, _ int) error {
+		c := atomic.AddInt32(&current, 1)
+		for {
+			m := atomic.LoadInt32(&maxSeen)
+			if c <= m || atomic.CompareAndSwapInt32(&maxSeen, m, c) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		return nil
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int32(limit), maxSeen)
+}
+
+func TestRun_rate(t *testing.T) {
+	const rate = 20.0 // one start every 50ms
+	interval := time.Duration(float64(time.Second) / rate)
+	var mu sync.Mutex
+	var starts []time.Time
+	err := Run(context.Background(), Config{Rate: rate}, items(6), func(_ context.Context, _ int) error {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+		return nil
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, starts, 6)
+	for i := 1; i < len(starts); i++ {
+		// allow for some timer granularity
+		assert.GreaterOrEqual(t, starts[i].Sub(starts[i-1]), interval-5*time.Millisecond)
+	}
+}
+
+func TestRun_progressAndErrors(t *testing.T) {
+	var dones []int
+	var failed []int
+	err := Run(context.Background(), Config{Concurrency: 2}, items(5), func(_ context.Context, i int) error {
+		if i%2 == 1 {
+			return errors.New("odd")
+		}
+		return nil
+	}, func(done, total, item int, err error) {
+		assert.Equal(t, 5, total)
+		dones = append(dones, done)
+		if err != nil {
+			failed = append(failed, item)
+		}
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "multiple errors:")
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, dones)
+	assert.ElementsMatch(t, []int{1, 3}, failed)
+}
+
+func TestRun_cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+	err := Run(ctx, Config{Concurrency: 1}, items(5), func(_ context.Context, i int) error {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			cancel()
+		}
+		return nil
+	}, nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(2), calls)
+	assert.Contains(t, err.Error(), "3 of 5 operations were not started")
+}
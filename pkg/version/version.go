@@ -13,30 +13,49 @@ import (
 // init()-time by inspecting the binary's own debug info.
 var Version string
 
+// Values of Source.
+const (
+	SourceLdflags   = "ldflags"
+	SourceBuildInfo = "buildinfo"
+	SourceEnv       = "env"
+	SourceUnknown   = "unknown"
+)
+
+// Source tells where the Version was obtained from; the `--ldflags -X` given at build-time, the binary's
+// own debug info, the TELEPRESENCE_VERSION environment variable, or nowhere.
+var Source string
+
 func init() {
+	Version, Source = resolve(Version, debug.ReadBuildInfo, os.Getenv)
+}
+
+func resolve(ldflagsVersion string, readBuildInfo func() (*debug.BuildInfo, bool), getenv func(string) string) (string, string) {
 	// Prefer version number inserted at build using --ldflags, but if it's not set...
-	if Version == "" {
-		if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
-			// Fall back to version info from "go get"
-			Version = info.Main.Version
-		} else {
-			Version = "(unknown version)"
+	if ldflagsVersion != "" {
+		return ldflagsVersion, SourceLdflags
+	}
+	var version, source string
+	if info, ok := readBuildInfo(); ok && info.Main.Version != "" {
+		// Fall back to version info from "go get"
+		version, source = info.Main.Version, SourceBuildInfo
+	} else {
+		version, source = "(unknown version)", SourceUnknown
+	}
+	if _, err := semver.ParseTolerant(version); err != nil {
+		if version != "" && version != "(devel)" && version != "(unknown version)" {
+			// If this isn't a parsable semver (enforced by Makefile), isn't
+			// empty, "(devel)" (a special value from runtime/debug), or our own
+			// special "(unknown version)", then something about the toolchain
+			// has clearly changed and invalidated our assumptions.  That's
+			// worthy of a panic; if this is built using an unsupported
+			// compiler, we can't be sure of anything.
+			panic(fmt.Errorf("this binary's compiled-in version looks invalid: %w", err))
 		}
-		if _, err := semver.ParseTolerant(Version); err != nil {
-			if Version != "" && Version != "(devel)" && Version != "(unknown version)" {
-				// If this isn't a parsable semver (enforced by Makefile), isn't
-				// empty, "(devel)" (a special value from runtime/debug), or our own
-				// special "(unknown version)", then something about the toolchain
-				// has clearly changed and invalidated our assumptions.  That's
-				// worthy of a panic; if this is built using an unsupported
-				// compiler, we can't be sure of anything.
-				panic(fmt.Errorf("this binary's compiled-in version looks invalid: %w", err))
-			}
-			if env := os.Getenv("TELEPRESENCE_VERSION"); strings.HasPrefix(env, "v") {
-				Version = env
-			}
+		if env := getenv("TELEPRESENCE_VERSION"); strings.HasPrefix(env, "v") {
+			version, source = env, SourceEnv
 		}
 	}
+	return version, source
 }
 
 var (
@@ -0,0 +1,46 @@
+package version
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_resolve(t *testing.T) {
+	buildInfo := func(v string) func() (*debug.BuildInfo, bool) {
+		return func() (*debug.BuildInfo, bool) {
+			if v == "" {
+				return nil, false
+			}
+			return &debug.BuildInfo{Main: debug.Module{Version: v}}, true
+		}
+	}
+	env := func(v string) func(string) string {
+		return func(string) string { return v }
+	}
+
+	tests := []struct {
+		name       string
+		ldflags    string
+		buildInfo  string
+		env        string
+		wantVer    string
+		wantSource string
+	}{
+		{"ldflags", "v2.8.0", "v2.7.0", "v2.6.0", "v2.8.0", SourceLdflags},
+		{"build info", "", "v2.7.0", "v2.6.0", "v2.7.0", SourceBuildInfo},
+		{"devel uses env", "", "(devel)", "v2.6.0", "v2.6.0", SourceEnv},
+		{"devel without env", "", "(devel)", "", "(devel)", SourceBuildInfo},
+		{"unknown uses env", "", "", "v2.6.0", "v2.6.0", SourceEnv},
+		{"unknown", "", "", "2.6.0", "(unknown version)", SourceUnknown},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			v, s := resolve(tt.ldflags, buildInfo(tt.buildInfo), env(tt.env))
+			assert.Equal(t, tt.wantVer, v)
+			assert.Equal(t, tt.wantSource, s)
+		})
+	}
+}
@@ -85,6 +85,9 @@ type ConnectorClient interface {
 	AddInterceptor(ctx context.Context, in *Interceptor, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// RemoveInterceptor removes a previously added interceptor
 	RemoveInterceptor(ctx context.Context, in *Interceptor, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Health reports the readiness of the daemons, the cluster connection, the outbound
+	// proxy, and each intercept
+	Health(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HealthReport, error)
 }
 
 type connectorClient struct {
@@ -366,6 +369,15 @@ func (c *connectorClient) RemoveInterceptor(ctx context.Context, in *Interceptor
 	return out, nil
 }
 
+func (c *connectorClient) Health(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HealthReport, error) {
+	out := new(HealthReport)
+	err := c.cc.Invoke(ctx, "/telepresence.connector.Connector/Health", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ConnectorServer is the server API for Connector service.
 // All implementations must embed UnimplementedConnectorServer
 // for forward compatibility
@@ -429,6 +441,9 @@ type ConnectorServer interface {
 	AddInterceptor(context.Context, *Interceptor) (*emptypb.Empty, error)
 	// RemoveInterceptor removes a previously added interceptor
 	RemoveInterceptor(context.Context, *Interceptor) (*emptypb.Empty, error)
+	// Health reports the readiness of the daemons, the cluster connection, the outbound
+	// proxy, and each intercept
+	Health(context.Context, *emptypb.Empty) (*HealthReport, error)
 	mustEmbedUnimplementedConnectorServer()
 }
 
@@ -511,6 +526,9 @@ func (UnimplementedConnectorServer) AddInterceptor(context.Context, *Interceptor
 func (UnimplementedConnectorServer) RemoveInterceptor(context.Context, *Interceptor) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RemoveInterceptor not implemented")
 }
+func (UnimplementedConnectorServer) Health(context.Context, *emptypb.Empty) (*HealthReport, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
 func (UnimplementedConnectorServer) mustEmbedUnimplementedConnectorServer() {}
 
 // UnsafeConnectorServer may be embedded to opt out of forward compatibility for this service.
@@ -980,6 +998,24 @@ func _Connector_RemoveInterceptor_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Connector_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConnectorServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/telepresence.connector.Connector/Health",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConnectorServer).Health(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Connector_ServiceDesc is the grpc.ServiceDesc for Connector service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -1079,6 +1115,10 @@ var Connector_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RemoveInterceptor",
 			Handler:    _Connector_RemoveInterceptor_Handler,
 		},
+		{
+			MethodName: "Health",
+			Handler:    _Connector_Health_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{